@@ -1,26 +1,281 @@
 package errs
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Code classifies an error
+type Code string
+
+const (
+	CodeUnknown            Code = ""
+	CodeNotFound           Code = "not_found"
+	CodeBadArgs            Code = "bad_args"
+	CodeNotAuthenticated   Code = "not_authenticated"
+	CodeForbidden          Code = "forbidden"
+	CodeConflict           Code = "conflict"
+	CodePreconditionFailed Code = "precondition_failed"
+	CodeTooManyRequests    Code = "too_many_requests"
+	CodeUnavailable        Code = "unavailable"
+	CodeTimeout            Code = "timeout"
+	CodeInternal           Code = "internal"
+)
+
+var codeTexts = map[Code]string{
+	CodeUnknown:            "unknown error",
+	CodeNotFound:           "not found",
+	CodeBadArgs:            "bad arguments",
+	CodeNotAuthenticated:   "not authenticated",
+	CodeForbidden:          "forbidden",
+	CodeConflict:           "conflict",
+	CodePreconditionFailed: "precondition failed",
+	CodeTooManyRequests:    "too many requests",
+	CodeUnavailable:        "unavailable",
+	CodeTimeout:            "timeout",
+	CodeInternal:           "internal error",
+}
+
+var codeStatus = map[Code]int{
+	CodeNotFound:           http.StatusNotFound,
+	CodeBadArgs:            http.StatusBadRequest,
+	CodeNotAuthenticated:   http.StatusUnauthorized,
+	CodeForbidden:          http.StatusForbidden,
+	CodeConflict:           http.StatusConflict,
+	CodePreconditionFailed: http.StatusPreconditionFailed,
+	CodeTooManyRequests:    http.StatusTooManyRequests,
+	CodeUnavailable:        http.StatusServiceUnavailable,
+	CodeTimeout:            http.StatusGatewayTimeout,
+	CodeInternal:           http.StatusInternalServerError,
+}
+
+// String returns the text of the code, e.g. "not found"
+func (c Code) String() string {
+	if s, ok := codeTexts[c]; ok {
+		return s
+	}
+	return string(c)
+}
+
+// FieldError describes an invalid field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is an error with a code, an optional message, field errors and a cause.
+// Errors with the same code match with errors.Is, e.g. errors.Is(NotFoundf("foo %q", id), NotFound()).
+type Error struct {
+	code   Code
+	msg    string
+	fields []FieldError
+	cause  error
+}
+
+// New creates an error with code and message
+func New(code Code, msg string) *Error {
+	return &Error{code: code, msg: msg}
+}
 
+// Newf creates an error with code and formatted message
+func Newf(code Code, format string, args ...any) *Error {
+	return New(code, fmt.Sprintf(format, args...))
+}
+
+func (e *Error) Code() Code {
+	return e.code
+}
+
+func (e *Error) Message() string {
+	return e.msg
+}
+
+func (e *Error) Fields() []FieldError {
+	return e.fields
+}
+
+// WithField returns a copy of e with an additional field error
+func (e *Error) WithField(field, msg string) *Error {
+	cp := *e
+	cp.fields = append(append([]FieldError{}, e.fields...), FieldError{Field: field, Message: msg})
+	return &cp
+}
+
+// WithCause returns a copy of e wrapping cause
+func (e *Error) WithCause(cause error) *Error {
+	cp := *e
+	cp.cause = cause
+	return &cp
+}
+
+func (e *Error) Error() string {
+	sb := strings.Builder{}
+	sb.WriteString(e.code.String())
+	if e.msg != "" {
+		sb.WriteString(": ")
+		sb.WriteString(e.msg)
+	}
+	for i, f := range e.fields {
+		if i == 0 {
+			sb.WriteString(": ")
+		} else {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "%s: %s", f.Field, f.Message)
+	}
+	if e.cause != nil {
+		sb.WriteString(": ")
+		sb.WriteString(e.cause.Error())
+	}
+	return sb.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is an *Error with the same code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.code == e.code
+}
+
+// HTTPStatus returns the http status code of e
+func (e *Error) HTTPStatus() int {
+	if s, ok := codeStatus[e.code]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+// sentinels returned by the constructors without message; use New to create an *Error with field errors or a cause
 var (
-	errNotFound         error = fmt.Errorf("not found")
-	errBadArgs          error = fmt.Errorf("bad arguments")
-	errNotAuthenticated error = fmt.Errorf("not authenticated")
-	errForbidden        error = fmt.Errorf("forbidden")
+	errNotFound           = New(CodeNotFound, "")
+	errBadArgs            = New(CodeBadArgs, "")
+	errNotAuthenticated   = New(CodeNotAuthenticated, "")
+	errForbidden          = New(CodeForbidden, "")
+	errConflict           = New(CodeConflict, "")
+	errPreconditionFailed = New(CodePreconditionFailed, "")
+	errTooManyRequests    = New(CodeTooManyRequests, "")
+	errUnavailable        = New(CodeUnavailable, "")
+	errTimeout            = New(CodeTimeout, "")
+	errInternal           = New(CodeInternal, "")
 )
 
-func NotFound() error {
-	return errNotFound
+var sentinels = map[Code]*Error{
+	CodeNotFound:           errNotFound,
+	CodeBadArgs:            errBadArgs,
+	CodeNotAuthenticated:   errNotAuthenticated,
+	CodeForbidden:          errForbidden,
+	CodeConflict:           errConflict,
+	CodePreconditionFailed: errPreconditionFailed,
+	CodeTooManyRequests:    errTooManyRequests,
+	CodeUnavailable:        errUnavailable,
+	CodeTimeout:            errTimeout,
+	CodeInternal:           errInternal,
+}
+
+func NotFound() error { return errNotFound }
+
+func NotFoundf(format string, args ...any) error { return Newf(CodeNotFound, format, args...) }
+
+func BadArgs() error { return errBadArgs }
+
+func BadArgsf(format string, args ...any) error { return Newf(CodeBadArgs, format, args...) }
+
+func NotAuthenticated() error { return errNotAuthenticated }
+
+func NotAuthenticatedf(format string, args ...any) error {
+	return Newf(CodeNotAuthenticated, format, args...)
+}
+
+func Forbidden() error { return errForbidden }
+
+func Forbiddenf(format string, args ...any) error { return Newf(CodeForbidden, format, args...) }
+
+func Conflict() error { return errConflict }
+
+func Conflictf(format string, args ...any) error { return Newf(CodeConflict, format, args...) }
+
+func PreconditionFailed() error { return errPreconditionFailed }
+
+func PreconditionFailedf(format string, args ...any) error {
+	return Newf(CodePreconditionFailed, format, args...)
+}
+
+func TooManyRequests() error { return errTooManyRequests }
+
+func TooManyRequestsf(format string, args ...any) error {
+	return Newf(CodeTooManyRequests, format, args...)
+}
+
+func Unavailable() error { return errUnavailable }
+
+func Unavailablef(format string, args ...any) error { return Newf(CodeUnavailable, format, args...) }
+
+func Timeout() error { return errTimeout }
+
+func Timeoutf(format string, args ...any) error { return Newf(CodeTimeout, format, args...) }
+
+func Internal() error { return errInternal }
+
+func Internalf(format string, args ...any) error { return Newf(CodeInternal, format, args...) }
+
+// CodeOf returns the code of the first *Error in the chain of err. context.DeadlineExceeded maps to CodeTimeout.
+func CodeOf(err error) Code {
+	var e *Error
+	switch {
+	case err == nil:
+		return CodeUnknown
+	case errors.As(err, &e):
+		return e.code
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	default:
+		return CodeUnknown
+	}
 }
 
-func BadArgs() error {
-	return errBadArgs
+// FieldsOf returns the field errors of the first *Error in the chain of err
+func FieldsOf(err error) []FieldError {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.fields
+	}
+	return nil
 }
 
-func NotAuthenticated() error {
-	return errNotAuthenticated
+// HTTPStatus maps err to a http status code. Errors without a known code map to 500.
+func HTTPStatus(err error) int {
+	if s, ok := codeStatus[CodeOf(err)]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
 }
 
-func Forbidden() error {
-	return errForbidden
+// FromHTTPStatus maps a http status code to an error. It returns nil for non-error status codes.
+// Unmapped 4xx codes map to CodeBadArgs, unmapped 5xx codes to CodeInternal.
+func FromHTTPStatus(status int) error {
+	switch {
+	case status < 400:
+		return nil
+	case status == http.StatusGone:
+		return NotFound()
+	case status == http.StatusRequestTimeout:
+		return Timeout()
+	case status == http.StatusBadGateway:
+		return Unavailable()
+	}
+	for c, s := range codeStatus {
+		if s == status {
+			return sentinels[c]
+		}
+	}
+	if status < 500 {
+		return BadArgs()
+	}
+	return Internal()
 }
@@ -1,8 +1,11 @@
 package errs
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"reflect"
 	"testing"
 )
 
@@ -13,8 +16,87 @@ func TestErrs(t *testing.T) {
 		t.Fatalf("errors not equal")
 	}
 
+	if err1 != err2 || NotFound() == BadArgs() {
+		t.Fatalf("want shared sentinels")
+	}
+	if FromHTTPStatus(http.StatusConflict) != Conflict() {
+		t.Fatalf("want conflict sentinel")
+	}
+
 	err3 := fmt.Errorf("wrap no found: %w", err1)
 	if !errors.Is(err3, err1) {
 		t.Fatalf("error not wrapped")
 	}
 }
+
+func TestErrorCodes(t *testing.T) {
+	err := fmt.Errorf("get foo: %w", NotFoundf("foo %q", "42"))
+	if !errors.Is(err, NotFound()) {
+		t.Fatalf("want not-found")
+	}
+	if errors.Is(err, Conflict()) {
+		t.Fatalf("want no conflict")
+	}
+	if want, have := `get foo: not found: foo "42"`, err.Error(); want != have {
+		t.Fatalf("want %q, have %q", want, have)
+	}
+	if want, have := CodeNotFound, CodeOf(err); want != have {
+		t.Fatalf("want %q, have %q", want, have)
+	}
+	if want, have := CodeTimeout, CodeOf(context.DeadlineExceeded); want != have {
+		t.Fatalf("want %q, have %q", want, have)
+	}
+}
+
+func TestErrorFieldsAndCause(t *testing.T) {
+	cause := errors.New("no rows")
+	err := New(CodeBadArgs, "").WithField("name", "required").WithField("age", "min 0").WithCause(cause)
+	if want, have := "bad arguments: name: required, age: min 0: no rows", err.Error(); want != have {
+		t.Fatalf("want %q, have %q", want, have)
+	}
+	if !errors.Is(err, cause) || !errors.Is(err, BadArgs()) {
+		t.Fatalf("want bad-args with cause")
+	}
+	want := []FieldError{{Field: "name", Message: "required"}, {Field: "age", Message: "min 0"}}
+	if have := FieldsOf(fmt.Errorf("wrapped: %w", err)); !reflect.DeepEqual(want, have) {
+		t.Fatalf("want %v, have %v", want, have)
+	}
+	if len(FieldsOf(BadArgs())) != 0 {
+		t.Fatalf("want sentinel unchanged")
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{NotFound(), http.StatusNotFound},
+		{BadArgs(), http.StatusBadRequest},
+		{NotAuthenticated(), http.StatusUnauthorized},
+		{Forbidden(), http.StatusForbidden},
+		{Conflict(), http.StatusConflict},
+		{PreconditionFailed(), http.StatusPreconditionFailed},
+		{TooManyRequests(), http.StatusTooManyRequests},
+		{Unavailable(), http.StatusServiceUnavailable},
+		{Timeout(), http.StatusGatewayTimeout},
+		{Internal(), http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		if have := HTTPStatus(test.err); have != test.status {
+			t.Fatalf("%v: want %d, have %d", test.err, test.status, have)
+		}
+		if have := FromHTTPStatus(test.status); !errors.Is(have, test.err) {
+			t.Fatalf("%d: want %v, have %v", test.status, test.err, have)
+		}
+	}
+	if have := HTTPStatus(errors.New("x")); have != http.StatusInternalServerError {
+		t.Fatalf("want 500, have %d", have)
+	}
+	if have := FromHTTPStatus(http.StatusTeapot); !errors.Is(have, BadArgs()) {
+		t.Fatalf("want bad-args, have %v", have)
+	}
+	if have := FromHTTPStatus(http.StatusNoContent); have != nil {
+		t.Fatalf("want nil, have %v", have)
+	}
+}
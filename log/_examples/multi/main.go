@@ -23,8 +23,7 @@ func createLogger() *log.DefaultLogger {
 	}
 	ef := log.NewFilter(
 		func(e entry.Entry) bool {
-			return e.Level == entry.LevelError ||
-				e.Level == entry.LevelFatal
+			return e.Level >= entry.LevelError
 		},
 		ew,
 	)
@@ -46,6 +45,8 @@ func main() {
 	log.Errorf("a simple error message %f", 1.23)
 	defer log.Fatalf("finally ... %v", fmt.Errorf("an expected error occurred"))
 
+	log.With("order_id", "o-4711", "items", 3).Infof("order placed")
+
 	c := newComponent("comp-x")
 	c.do()
 }
@@ -66,5 +67,5 @@ func newComponent(name string) *component {
 func (c *component) do() {
 	c.Infof("did stuff")
 	c.Warnf("go to work in %s", 5*time.Minute)
-	c.Errorf("go to work failed ...")
+	c.With("attempt", 2).Errorf("go to work failed ...")
 }
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"syscall"
+	"time"
+
 	"github.com/best4tires/kit/log"
+	"github.com/best4tires/kit/log/entry"
 	"github.com/best4tires/kit/log/rotate"
 )
 
@@ -14,9 +18,22 @@ func createLogger() *log.DefaultLogger {
 	if err != nil {
 		panic(err)
 	}
+	aw, err := rotate.NewWriter(
+		"logs/access.log",
+		rotate.WithInterval(rotate.Daily),
+		rotate.WithCompression(),
+		rotate.WithMaxAge(30*24*time.Hour),
+		rotate.WithRotateSignals(syscall.SIGHUP),
+	)
+	if err != nil {
+		panic(err)
+	}
 	return log.NewDefaultLogger(
 		"rotate-example",
-		w,
+		log.NewMultiWriter(
+			w,
+			log.NewFilter(func(e entry.Entry) bool { return e.Level == entry.LevelAccess }, aw),
+		),
 	)
 }
 
@@ -0,0 +1,136 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/best4tires/kit/log/entry"
+)
+
+// OverflowPolicy defines how an AsyncWriter behaves, if its buffer is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the caller until there's room in the buffer
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards the entry to be written
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest buffered entry to make room for the entry to be written
+	OverflowDropOldest
+)
+
+// AsyncOption is the AsyncWriter option type
+type AsyncOption func(w *AsyncWriter)
+
+// WithBufferSize defines the number of entries buffered by the AsyncWriter
+func WithBufferSize(n int) AsyncOption {
+	return func(w *AsyncWriter) {
+		if n > 0 {
+			w.bufferSize = n
+		}
+	}
+}
+
+// WithOverflowPolicy defines the behaviour of the AsyncWriter, if its buffer is full
+func WithOverflowPolicy(p OverflowPolicy) AsyncOption {
+	return func(w *AsyncWriter) {
+		w.policy = p
+	}
+}
+
+// AsyncStats holds the counters of an AsyncWriter
+type AsyncStats struct {
+	Written uint64
+	Dropped uint64
+}
+
+// AsyncWriter is a writer middleware, which decouples callers from the next writer by a buffer and a background goroutine
+type AsyncWriter struct {
+	next       Writer
+	bufferSize int
+	policy     OverflowPolicy
+	entryC     chan entry.Entry
+	doneC      chan struct{}
+	mu         sync.RWMutex
+	closed     bool
+	written    atomic.Uint64
+	dropped    atomic.Uint64
+}
+
+// NewAsyncWriter creates a new AsyncWriter writing to next. It defaults to a buffer of 10000 entries and OverflowBlock.
+func NewAsyncWriter(next Writer, opts ...AsyncOption) *AsyncWriter {
+	w := &AsyncWriter{
+		next:       next,
+		bufferSize: 10000,
+		policy:     OverflowBlock,
+		doneC:      make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	w.entryC = make(chan entry.Entry, w.bufferSize)
+	go func() {
+		defer close(w.doneC)
+		for e := range w.entryC {
+			w.next.Write(e)
+			w.written.Add(1)
+		}
+	}()
+	return w
+}
+
+// Write buffers an entry according to the overflow policy. Entries written after Close are dropped.
+func (w *AsyncWriter) Write(e entry.Entry) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		w.dropped.Add(1)
+		return
+	}
+	switch w.policy {
+	case OverflowDropNewest:
+		select {
+		case w.entryC <- e:
+		default:
+			w.dropped.Add(1)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case w.entryC <- e:
+				return
+			default:
+			}
+			select {
+			case <-w.entryC:
+				w.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		w.entryC <- e
+	}
+}
+
+// Close flushes all buffered entries and closes the next writer
+func (w *AsyncWriter) Close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	close(w.entryC)
+	w.mu.Unlock()
+
+	<-w.doneC
+	w.next.Close()
+}
+
+// Stats returns the number of written and dropped entries
+func (w *AsyncWriter) Stats() AsyncStats {
+	return AsyncStats{
+		Written: w.written.Load(),
+		Dropped: w.dropped.Load(),
+	}
+}
@@ -0,0 +1,92 @@
+package log
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/testutil"
+)
+
+// gateWriter blocks all writes until the gate is opened
+type gateWriter struct {
+	gate   chan struct{}
+	mu     sync.Mutex
+	msgs   []string
+	closed bool
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{gate: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(e entry.Entry) {
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.msgs = append(w.msgs, e.Message)
+}
+
+func (w *gateWriter) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+}
+
+func TestAsyncWriterPolicies(t *testing.T) {
+	tests := []struct {
+		policy  OverflowPolicy
+		written []string
+		dropped uint64
+	}{
+		// "m0" is taken by the worker and blocks, "m1" and "m2" fill the buffer
+		{policy: OverflowDropNewest, written: []string{"m0", "m1", "m2"}, dropped: 3},
+		{policy: OverflowDropOldest, written: []string{"m0", "m4", "m5"}, dropped: 3},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("policy_%d", test.policy), func(t *testing.T) {
+			gw := newGateWriter()
+			w := NewAsyncWriter(gw, WithBufferSize(2), WithOverflowPolicy(test.policy))
+			w.Write(entry.Make(entry.LevelInfo, "m0"))
+			// wait until the worker picked up m0
+			for len(w.entryC) > 0 {
+				runtime.Gosched()
+			}
+			for i := 1; i <= 5; i++ {
+				w.Write(entry.Make(entry.LevelInfo, "m%d", i))
+			}
+			close(gw.gate)
+			w.Close()
+
+			testutil.AssertEqual(t, test.written, gw.msgs)
+			testutil.AssertEqual(t, AsyncStats{Written: uint64(len(test.written)), Dropped: test.dropped}, w.Stats())
+			testutil.AssertEqual(t, true, gw.closed)
+		})
+	}
+}
+
+func TestAsyncWriterBlockFlushesOnClose(t *testing.T) {
+	gw := newGateWriter()
+	close(gw.gate)
+	w := NewAsyncWriter(gw, WithBufferSize(4))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				w.Write(entry.Make(entry.LevelInfo, "msg"))
+			}
+		}()
+	}
+	wg.Wait()
+	w.Close()
+	w.Write(entry.Make(entry.LevelInfo, "after close"))
+	w.Close()
+
+	testutil.AssertEqual(t, 1000, len(gw.msgs))
+	testutil.AssertEqual(t, AsyncStats{Written: 1000, Dropped: 1}, w.Stats())
+}
@@ -0,0 +1,12 @@
+package log
+
+// SetReportCaller enables or disables recording the caller's file, line and function on each entry of the default logger.
+// It's disabled by default, since determining the caller is rather expensive.
+func SetReportCaller(on bool) {
+	std.SetReportCaller(on)
+}
+
+// ReportCaller returns, if caller reporting is enabled for the default logger
+func ReportCaller() bool {
+	return std.ReportCaller()
+}
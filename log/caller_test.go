@@ -0,0 +1,52 @@
+package log
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestReportCaller(t *testing.T) {
+	rw := installRecorder(t)
+	defer SetReportCaller(false)
+
+	Infof("disabled")
+	SetReportCaller(true)
+	Infof("package-func")
+	ComponentHook("comp").With("k", "v").Infof("hook")
+	DebugStack()
+	Installed().Log(entry.Make(entry.LevelInfo, "default-logger"))
+
+	testutil.AssertEqual(t, 5, len(rw.entries))
+	testutil.AssertEqual(t, (*entry.Caller)(nil), rw.entries[0].Caller)
+	for _, e := range rw.entries[1:] {
+		if e.Caller == nil {
+			t.Fatalf("%q: no caller", e.Message)
+		}
+		testutil.AssertEqual(t, "github.com/best4tires/kit/log.TestReportCaller", e.Caller.Function)
+		testutil.AssertEqual(t, "log.TestReportCaller", e.Caller.ShortFunction())
+		if !strings.HasPrefix(e.Caller.String(), "log/caller_test.go:") {
+			t.Fatalf("%q: unexpected location %q", e.Message, e.Caller.String())
+		}
+	}
+}
+
+func TestReportCallerInstance(t *testing.T) {
+	SetReportCaller(true)
+	defer SetReportCaller(false)
+
+	rw := &recordWriter{}
+	l := NewWithWriter("instance", rw)
+	l.Infof("disabled")
+	l.SetReportCaller(true)
+	l.Infof("enabled")
+
+	testutil.AssertEqual(t, 2, len(rw.entries))
+	testutil.AssertEqual(t, (*entry.Caller)(nil), rw.entries[0].Caller)
+	if rw.entries[1].Caller == nil {
+		t.Fatalf("no caller")
+	}
+	testutil.AssertEqual(t, "log.TestReportCallerInstance", rw.entries[1].Caller.ShortFunction())
+}
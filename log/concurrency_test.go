@@ -0,0 +1,84 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/best4tires/kit/log/console"
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/testutil"
+)
+
+// lockedBuffer is a stream, which detects unserialized writes
+type lockedBuffer struct {
+	mu     sync.Mutex
+	inUse  bool
+	buf    bytes.Buffer
+	racing bool
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	if b.inUse {
+		b.racing = true
+	}
+	b.inUse = true
+	b.mu.Unlock()
+	// write byte by byte to provoke interleaving
+	for i := range p {
+		b.buf.WriteByte(p[i])
+	}
+	b.mu.Lock()
+	b.inUse = false
+	b.mu.Unlock()
+	return len(p), nil
+}
+
+func (b *lockedBuffer) Close() error { return nil }
+
+// Run with "go test -race" to detect data races
+func TestConcurrentLoggingAndInstall(t *testing.T) {
+	prev := Installed()
+	defer Install(prev)
+
+	stream := &lockedBuffer{}
+	cw := console.NewWriter(console.WithStream(stream), console.WithFormatter(console.TextFormatter{}))
+	mw := NewMultiWriter(cw, NewFilter(func(e entry.Entry) bool { return e.Level >= entry.LevelWarn }, &recordWriter{}))
+	sinks := []Sink{NewDefaultLogger("a", mw), NewDefaultLogger("b", mw)}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l := Named(fmt.Sprintf("worker-%d", i))
+			for j := 0; j < 200; j++ {
+				switch j % 3 {
+				case 0:
+					Infof("info %d", j)
+				case 1:
+					l.With("j", j).Warnf("warn")
+				default:
+					Install(sinks[j%2])
+					SetComponentLevel(fmt.Sprintf("worker-%d", i), entry.LevelInfo)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	mw.Close()
+	// writes after close are discarded
+	Infof("after close")
+	mw.Close()
+
+	testutil.AssertEqual(t, false, stream.racing)
+	lines := strings.Split(strings.TrimSuffix(stream.buf.String(), "\n"), "\n")
+	for _, line := range lines {
+		if !strings.Contains(line, "] info ") && !strings.Contains(line, "] warn j=") {
+			t.Fatalf("garbled line %q", line)
+		}
+	}
+}
@@ -9,7 +9,18 @@ import (
 type TextFormatter struct{}
 
 func (f TextFormatter) Format(e entry.Entry) string {
-	return fmt.Sprintf("%s [%s] [%s] [%s] %s", e.Time.Format("2006-01-02T15:04:05.000"), e.Program, e.Component, e.Level, e.Message)
+	return formatText(e)
+}
+
+func formatText(e entry.Entry) string {
+	s := fmt.Sprintf("%s [%s] [%s] [%s] %s", e.Time.Format("2006-01-02T15:04:05.000"), e.Program, e.Component, e.Level, e.Message)
+	if len(e.Fields) > 0 {
+		s += " " + e.Fields.String()
+	}
+	if e.Caller != nil {
+		s += " caller=" + e.Caller.String() + " func=" + e.Caller.ShortFunction()
+	}
+	return s
 }
 
 const (
@@ -48,8 +59,7 @@ func (f ColorFormatter) Format(e entry.Entry) string {
 	default:
 		cr = fgWhite
 	}
-	s := fmt.Sprintf("%s [%s] [%s] [%s] %s", e.Time.Format("2006-01-02T15:04:05.000"), e.Program, e.Component, e.Level, e.Message)
-	return f.colored(cr, s)
+	return f.colored(cr, formatText(e))
 }
 
 func (f ColorFormatter) colored(cr int, s string) string {
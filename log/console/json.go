@@ -0,0 +1,96 @@
+package console
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/best4tires/kit/log/entry"
+)
+
+// JSONFormatter implements the "Formatter" interface and formats an entry as a single-line JSON object.
+// Fields are written as top-level members; fields colliding with the standard members are prefixed with "fields.".
+type JSONFormatter struct{}
+
+var jsonReservedKeys = map[string]bool{
+	"time":      true,
+	"level":     true,
+	"program":   true,
+	"component": true,
+	"message":   true,
+	"caller":    true,
+}
+
+type jsonCaller struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+}
+
+// Format formats an entry
+func (f JSONFormatter) Format(e entry.Entry) string {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	writeJSONMember(buf, "time", e.Time.Format(time.RFC3339Nano), true)
+	writeJSONMember(buf, "level", e.Level.Name(), false)
+	writeJSONMember(buf, "program", e.Program, false)
+	writeJSONMember(buf, "component", e.Component, false)
+	writeJSONMember(buf, "message", e.Message, false)
+	if e.Caller != nil {
+		writeJSONMember(buf, "caller", jsonCaller{File: e.Caller.File, Line: e.Caller.Line, Function: e.Caller.Function}, false)
+	}
+
+	// later fields win over earlier ones with the same key
+	for i, fld := range e.Fields {
+		if last := lastFieldIndex(e.Fields, fld.Key); last != i {
+			continue
+		}
+		key := fld.Key
+		if jsonReservedKeys[key] {
+			key = "fields." + key
+		}
+		writeJSONMember(buf, key, jsonValue(fld.Value), false)
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+func lastFieldIndex(fs entry.Fields, key string) int {
+	for i := len(fs) - 1; i >= 0; i-- {
+		if fs[i].Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func writeJSONMember(buf *bytes.Buffer, key string, value any, first bool) {
+	if !first {
+		buf.WriteByte(',')
+	}
+	kbs, _ := json.Marshal(key)
+	buf.Write(kbs)
+	buf.WriteByte(':')
+	vbs, err := json.Marshal(value)
+	if err != nil {
+		vbs, _ = json.Marshal(fmt.Sprintf("%v", value))
+	}
+	buf.Write(vbs)
+}
+
+// jsonValue converts field values, which would not be rendered meaningful by encoding/json
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case error:
+		return v.Error()
+	case json.Marshaler:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return v
+	}
+}
@@ -0,0 +1,46 @@
+package console
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	e := entry.Entry{
+		Time:      time.Date(2023, 4, 5, 6, 7, 8, 9000000, time.UTC),
+		Level:     entry.LevelInfo,
+		Program:   "prog",
+		Component: "comp",
+		Message:   "hello \"world\"",
+		Fields: entry.MakeFields(
+			"order_id", "o-1",
+			"count", 3,
+			"took", 1500*time.Millisecond,
+			"err", errors.New("boom"),
+			"message", "shadowed",
+			"count", 4,
+		),
+	}
+	s := JSONFormatter{}.Format(e)
+
+	var have map[string]any
+	err := json.Unmarshal([]byte(s), &have)
+	testutil.AssertNoErr(t, err, "unmarshal %q", s)
+	testutil.AssertEqual(t, map[string]any{
+		"time":           "2023-04-05T06:07:08.009Z",
+		"level":          "INFO",
+		"program":        "prog",
+		"component":      "comp",
+		"message":        "hello \"world\"",
+		"order_id":       "o-1",
+		"count":          float64(4),
+		"took":           "1.5s",
+		"err":            "boom",
+		"fields.message": "shadowed",
+	}, have)
+}
@@ -3,6 +3,7 @@ package console
 import (
 	"io"
 	"os"
+	"sync"
 
 	"github.com/best4tires/kit/log/entry"
 )
@@ -33,6 +34,8 @@ func WithFormatter(f Formatter) Option {
 type Writer struct {
 	writer    io.WriteCloser
 	formatter Formatter
+	mu        sync.Mutex
+	closed    bool
 }
 
 // NewWriter creates a new console-writer with the provided options
@@ -59,10 +62,22 @@ func NewWriter(opts ...Option) *Writer {
 
 // Close closes the Writer
 func (w *Writer) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
 	w.writer.Close()
 }
 
-// Write writes a log.Entry to the writer stream
+// Write writes a log.Entry to the writer stream. Concurrent writes are serialized, entries written after Close are discarded.
 func (w *Writer) Write(e entry.Entry) {
-	w.writer.Write([]byte(w.formatter.Format(e) + "\n"))
+	bs := []byte(w.formatter.Format(e) + "\n")
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.writer.Write(bs)
 }
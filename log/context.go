@@ -0,0 +1,39 @@
+package log
+
+import (
+	"context"
+
+	"github.com/best4tires/kit/log/entry"
+)
+
+const (
+	// FieldTraceID is the field key holding the W3C trace id of the current request
+	FieldTraceID = "trace_id"
+	// FieldSpanID is the field key holding the W3C span id of the current request
+	FieldSpanID = "span_id"
+)
+
+type fieldsCtxKey struct{}
+
+// WithContext returns a copy of ctx, which carries the passed key/value pairs in addition to the fields already carried by ctx
+func WithContext(ctx context.Context, kvs ...any) context.Context {
+	if len(kvs) == 0 {
+		return ctx
+	}
+	fs := ContextFields(ctx)
+	nfs := make(entry.Fields, 0, len(fs)+(len(kvs)+1)/2)
+	nfs = append(nfs, fs...)
+	nfs = append(nfs, entry.MakeFields(kvs...)...)
+	return context.WithValue(ctx, fieldsCtxKey{}, nfs)
+}
+
+// ContextFields returns the fields carried by ctx
+func ContextFields(ctx context.Context) entry.Fields {
+	fs, _ := ctx.Value(fieldsCtxKey{}).(entry.Fields)
+	return fs
+}
+
+// FromContext returns a child of the default logger, which amends log entries with the fields carried by ctx
+func FromContext(ctx context.Context) *Logger {
+	return std.FromContext(ctx)
+}
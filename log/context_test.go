@@ -0,0 +1,25 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestContextFields(t *testing.T) {
+	rw := installRecorder(t)
+
+	ctx := WithContext(context.Background(), "request_id", "r-1")
+	child := WithContext(ctx, "user", "bob")
+
+	FromContext(context.Background()).Infof("no fields")
+	FromContext(ctx).Infof("parent")
+	FromContext(child).With("n", 1).Infof("child")
+
+	testutil.AssertEqual(t, 3, len(rw.entries))
+	testutil.AssertEqual(t, entry.Fields(nil), rw.entries[0].Fields)
+	testutil.AssertEqual(t, entry.MakeFields("request_id", "r-1"), rw.entries[1].Fields)
+	testutil.AssertEqual(t, entry.MakeFields("request_id", "r-1", "user", "bob", "n", 1), rw.entries[2].Fields)
+}
@@ -10,13 +10,13 @@ type Writer interface {
 	Close()
 }
 
-// DefaultLogger is the default Logger implementation based on one Writer
+// DefaultLogger is the default Sink implementation based on one Writer
 type DefaultLogger struct {
 	name   string
 	writer Writer
 }
 
-// NewDefaultLogger creates a new StdLogger
+// NewDefaultLogger creates a new DefaultLogger
 func NewDefaultLogger(name string, w Writer) *DefaultLogger {
 	l := &DefaultLogger{
 		name:   name,
@@ -30,8 +30,16 @@ func (l *DefaultLogger) Close() {
 	l.writer.Close()
 }
 
-// Log logs writes an entry to the associated logger
+// Log writes an entry to the associated writer. The caller is recorded for direct calls, if enabled for the default logger.
 func (l *DefaultLogger) Log(e entry.Entry) {
+	if e.Caller == nil && std.ReportCaller() {
+		e.Caller = entry.CallerAt(1)
+	}
+	l.log(e)
+}
+
+// log writes an entry logged by a Logger, which records the caller according to its own setting
+func (l *DefaultLogger) log(e entry.Entry) {
 	if e.Program == "" {
 		e.Program = l.name
 	}
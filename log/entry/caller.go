@@ -0,0 +1,47 @@
+package entry
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Caller defines the source location a log.Entry was made at
+type Caller struct {
+	File     string
+	Line     int
+	Function string
+}
+
+// CallerAt returns the caller location, skipping the passed number of frames above the caller of CallerAt.
+// It returns nil, if the location cannot be determined.
+func CallerAt(skip int) *Caller {
+	pcs := [1]uintptr{}
+	if runtime.Callers(skip+2, pcs[:]) < 1 {
+		return nil
+	}
+	f, _ := runtime.CallersFrames(pcs[:]).Next()
+	if f.File == "" {
+		return nil
+	}
+	return &Caller{
+		File:     f.File,
+		Line:     f.Line,
+		Function: f.Function,
+	}
+}
+
+// String returns the short location, which is the last directory, the file name and the line, e.g. "log/hook.go:42"
+func (c Caller) String() string {
+	dir, file := filepath.Split(c.File)
+	return fmt.Sprintf("%s:%d", filepath.Join(filepath.Base(dir), file), c.Line)
+}
+
+// ShortFunction returns the function name without the package path, e.g. "log.Infof"
+func (c Caller) ShortFunction() string {
+	if i := strings.LastIndex(c.Function, "/"); i >= 0 {
+		return c.Function[i+1:]
+	}
+	return c.Function
+}
@@ -2,29 +2,170 @@ package entry
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// Level defines the level of a log.Entry
-type Level string
+// Level defines the level of a log.Entry. Levels are ordered by severity, so they may be compared against a threshold.
+type Level int
 
 const (
 	// LevelDebug denotes typical debug log entries
-	LevelDebug Level = "DEBUG"
+	LevelDebug Level = iota
 	// LevelInfo denotes just informational log entries
-	LevelInfo Level = "INFO "
+	LevelInfo
+	// LevelAccess denotes http-access messages
+	LevelAccess
+	// LevelImportant denotes important messages, which may be handled separately from usual INFO messages
+	LevelImportant
 	// LevelWarn denotes warnings, which are not as critical as errors.
-	LevelWarn Level = "WARN "
+	LevelWarn
 	// LevelError denotes errors on which the system keeps on running but action should be taken.
-	LevelError Level = "ERROR"
+	LevelError
 	// LevelFatal denotes fatal errors in which the system should panic immediately
-	LevelFatal Level = "FATAL"
-	// LevelAccess denotes http-access messages
-	LevelAccess Level = "ACCSS"
-	// LevelImportant denotes important messages, which may be handled separately from usual INFO messages
-	LevelImportant Level = "IMPNT"
+	LevelFatal
 )
 
+var levelLabels = map[Level]string{
+	LevelDebug:     "DEBUG",
+	LevelInfo:      "INFO ",
+	LevelAccess:    "ACCSS",
+	LevelImportant: "IMPNT",
+	LevelWarn:      "WARN ",
+	LevelError:     "ERROR",
+	LevelFatal:     "FATAL",
+}
+
+var levelNames = map[Level]string{
+	LevelDebug:     "DEBUG",
+	LevelInfo:      "INFO",
+	LevelAccess:    "ACCESS",
+	LevelImportant: "IMPORTANT",
+	LevelWarn:      "WARN",
+	LevelError:     "ERROR",
+	LevelFatal:     "FATAL",
+}
+
+// Levels returns all known levels ordered by severity
+func Levels() []Level {
+	return []Level{LevelDebug, LevelInfo, LevelAccess, LevelImportant, LevelWarn, LevelError, LevelFatal}
+}
+
+// String returns the fixed-width label of the level as used in text output, e.g. "INFO "
+func (l Level) String() string {
+	if s, ok := levelLabels[l]; ok {
+		return s
+	}
+	return fmt.Sprintf("L%03d", int(l))
+}
+
+// Name returns the name of the level, e.g. "INFO"
+func (l Level) Name() string {
+	if s, ok := levelNames[l]; ok {
+		return s
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// ParseLevel parses a level by its name or label, case-insensitive
+func ParseLevel(s string) (Level, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "WARNING" {
+		return LevelWarn, nil
+	}
+	for _, l := range Levels() {
+		if s == levelNames[l] || s == strings.TrimSpace(levelLabels[l]) {
+			return l, nil
+		}
+	}
+	return LevelDebug, fmt.Errorf("invalid level %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.Name()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (l *Level) UnmarshalText(text []byte) error {
+	pl, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = pl
+	return nil
+}
+
+// Field defines a typed key/value pair attached to a log.Entry
+type Field struct {
+	Key   string
+	Value any
+}
+
+// F creates a new Field
+func F(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Fields is a list of fields
+type Fields []Field
+
+// MakeFields creates fields from alternating key/value pairs. Non-string keys are formatted with %v,
+// a trailing key without value gets a nil value.
+func MakeFields(kvs ...any) Fields {
+	fs := make(Fields, 0, (len(kvs)+1)/2)
+	for i := 0; i < len(kvs); i += 2 {
+		key, ok := kvs[i].(string)
+		if !ok {
+			key = fmt.Sprintf("%v", kvs[i])
+		}
+		var value any
+		if i+1 < len(kvs) {
+			value = kvs[i+1]
+		}
+		fs = append(fs, Field{Key: key, Value: value})
+	}
+	return fs
+}
+
+// Value returns the value of the last field with the passed key, otherwise false
+func (fs Fields) Value(key string) (any, bool) {
+	for i := len(fs) - 1; i >= 0; i-- {
+		if fs[i].Key == key {
+			return fs[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// String formats the fields as space separated key=value pairs. Values containing spaces, quotes or
+// control characters are quoted.
+func (fs Fields) String() string {
+	sb := strings.Builder{}
+	for i, f := range fs {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(f.Key)
+		sb.WriteByte('=')
+		sb.WriteString(quoteValue(fmt.Sprintf("%v", f.Value)))
+	}
+	return sb.String()
+}
+
+func quoteValue(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
 // Entry defines a general log.Entry
 type Entry struct {
 	Time      time.Time
@@ -32,13 +173,30 @@ type Entry struct {
 	Program   string
 	Component string
 	Message   string
+	Fields    Fields
+	// Template is the format string the message was made from
+	Template string
+	// Caller is the source location the entry was made at, if caller reporting is enabled
+	Caller *Caller
 }
 
 // Make creates a new log entry with the passed parameters
 func Make(level Level, s string, args ...interface{}) Entry {
 	return Entry{
-		Time:    time.Now(),
-		Level:   level,
-		Message: fmt.Sprintf(s, args...),
+		Time:     time.Now(),
+		Level:    level,
+		Message:  fmt.Sprintf(s, args...),
+		Template: s,
+	}
+}
+
+// WithFields returns a copy of the entry with the passed fields appended. The fields of the original entry are not modified.
+func (e Entry) WithFields(fs ...Field) Entry {
+	if len(fs) == 0 {
+		return e
 	}
+	nfs := make(Fields, 0, len(e.Fields)+len(fs))
+	nfs = append(nfs, e.Fields...)
+	e.Fields = append(nfs, fs...)
+	return e
 }
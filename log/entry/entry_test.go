@@ -0,0 +1,53 @@
+package entry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestFieldsString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Fields
+		want string
+	}{
+		{name: "empty", in: nil, want: ""},
+		{name: "plain", in: MakeFields("id", 42, "user", "bob"), want: "id=42 user=bob"},
+		{name: "quoted", in: MakeFields("msg", "hello world", "empty", ""), want: `msg="hello world" empty=""`},
+		{name: "duration", in: Fields{F("took", 1500*time.Millisecond)}, want: "took=1.5s"},
+		{name: "missing value", in: MakeFields("id", 1, "dangling"), want: "id=1 dangling=<nil>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.AssertEqual(t, tt.want, tt.in.String())
+		})
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	base := Make(LevelInfo, "msg").WithFields(F("a", 1))
+	e1 := base.WithFields(F("b", 2))
+	e2 := base.WithFields(F("c", 3))
+
+	testutil.AssertEqual(t, Fields{F("a", 1)}, base.Fields)
+	testutil.AssertEqual(t, Fields{F("a", 1), F("b", 2)}, e1.Fields)
+	testutil.AssertEqual(t, Fields{F("a", 1), F("c", 3)}, e2.Fields)
+
+	v, ok := e1.Fields.Value("b")
+	testutil.AssertEqual(t, true, ok)
+	testutil.AssertEqual(t, 2, v)
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, l := range Levels() {
+		pl, err := ParseLevel(l.Name())
+		testutil.AssertNoErr(t, err, "parse %q", l.Name())
+		testutil.AssertEqual(t, l, pl)
+
+		pl, err = ParseLevel(l.String())
+		testutil.AssertNoErr(t, err, "parse %q", l.String())
+		testutil.AssertEqual(t, l, pl)
+	}
+}
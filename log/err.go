@@ -0,0 +1,90 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/best4tires/kit/log/entry"
+)
+
+const (
+	// FieldError is the field key holding the error message
+	FieldError = "error"
+	// FieldErrorChain is the field key holding the wrapped errors as "type: message" strings
+	FieldErrorChain = "error_chain"
+	// FieldStack is the field key holding the stack trace
+	FieldStack = "stack"
+)
+
+// Err creates a new child of the default logger, which attaches err and its chain of wrapped errors to log entries
+func Err(err error) *Logger {
+	return std.Err(err)
+}
+
+// Err returns a child logger, which attaches err and its chain of wrapped errors to log entries
+func (l *Logger) Err(err error) *Logger {
+	fs := ErrorFields(err)
+	return l.WithHook(func(e entry.Entry) entry.Entry {
+		return e.WithFields(fs...)
+	})
+}
+
+// WithStack returns a child logger, which attaches the stack of the caller to log entries
+func (l *Logger) WithStack() *Logger {
+	stack := Stack(1)
+	return l.WithHook(func(e entry.Entry) entry.Entry {
+		return e.WithFields(entry.F(FieldStack, stack))
+	})
+}
+
+// ErrorFields returns the fields describing err. The chain is only added, if err wraps other errors.
+func ErrorFields(err error) entry.Fields {
+	if err == nil {
+		return nil
+	}
+	fs := entry.Fields{entry.F(FieldError, err.Error())}
+	if chain := errorChain(err); len(chain) > 1 {
+		fs = append(fs, entry.F(FieldErrorChain, chain))
+	}
+	return fs
+}
+
+// errorChain walks the tree of wrapped errors depth-first, including errors joined by errors.Join
+func errorChain(err error) []string {
+	var chain []string
+	var walk func(err error)
+	walk = func(err error) {
+		if err == nil {
+			return
+		}
+		chain = append(chain, fmt.Sprintf("%T: %s", err, err.Error()))
+		switch x := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, e := range x.Unwrap() {
+				walk(e)
+			}
+		default:
+			walk(errors.Unwrap(err))
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// Stack returns the stack of the calling goroutine, skipping the passed number of frames above the caller of Stack
+func Stack(skip int) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	sb := strings.Builder{}
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
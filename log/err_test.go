@@ -0,0 +1,43 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestErr(t *testing.T) {
+	rw := installRecorder(t)
+
+	root := errors.New("connection refused")
+	wrapped := fmt.Errorf("query users: %w", root)
+	joined := errors.Join(wrapped, errors.New("rollback failed"))
+
+	Err(nil).Errorf("nil")
+	Err(root).Errorf("root")
+	ComponentHook("db").Err(joined).WithStack().Errorf("joined")
+
+	testutil.AssertEqual(t, 3, len(rw.entries))
+	testutil.AssertEqual(t, entry.Fields(nil), rw.entries[0].Fields)
+	testutil.AssertEqual(t, entry.MakeFields(FieldError, "connection refused"), rw.entries[1].Fields)
+
+	e := rw.entries[2]
+	testutil.AssertEqual(t, "db", e.Component)
+	testutil.AssertEqual(t, 3, len(e.Fields))
+	testutil.AssertEqual(t, entry.F(FieldError, joined.Error()), e.Fields[0])
+	testutil.AssertEqual(t, entry.F(FieldErrorChain, []string{
+		"*errors.joinError: query users: connection refused\nrollback failed",
+		"*fmt.wrapError: query users: connection refused",
+		"*errors.errorString: connection refused",
+		"*errors.errorString: rollback failed",
+	}), e.Fields[1])
+
+	stack, _ := e.Fields.Value(FieldStack)
+	if !strings.HasPrefix(stack.(string), "github.com/best4tires/kit/log.TestErr\n") {
+		t.Fatalf("stack does not start with caller: %s", stack)
+	}
+}
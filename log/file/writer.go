@@ -1,22 +1,36 @@
 package file
 
 import (
-	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 
+	"github.com/best4tires/kit/log/console"
 	"github.com/best4tires/kit/log/entry"
 )
 
+// Option is the file-writer option type
+type Option func(w *Writer)
+
+// WithFormatter configures the file writer to use the provided Formatter
+func WithFormatter(f console.Formatter) Option {
+	return func(w *Writer) {
+		w.formatter = f
+	}
+}
+
 // Writer implements the log.Writer interface.
 // It performs logging into a single log.file
 type Writer struct {
-	file io.WriteCloser
+	file      io.WriteCloser
+	formatter console.Formatter
+	mu        sync.Mutex
+	closed    bool
 }
 
-// NewWriter creates a new file.Writer
-func NewWriter(path string) (*Writer, error) {
+// NewWriter creates a new file.Writer. Entries are formatted with the console.TextFormatter unless configured otherwise.
+func NewWriter(path string, opts ...Option) (*Writer, error) {
 	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
 	if err != nil {
 		return nil, err
@@ -25,17 +39,34 @@ func NewWriter(path string) (*Writer, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Writer{
-		file: f,
-	}, nil
+	w := &Writer{
+		file:      f,
+		formatter: console.TextFormatter{},
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	return w, nil
 }
 
 // Close closes the associated file
 func (w *Writer) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
 	w.file.Close()
 }
 
-// Write writes an entry to the file
+// Write writes an entry to the file. Concurrent writes are serialized, entries written after Close are discarded.
 func (w *Writer) Write(e entry.Entry) {
-	fmt.Fprintf(w.file, "%s [%s] [%s] [%s] %s\n", e.Time.Format("2006-01-02T15:04:05.000"), e.Program, e.Component, e.Level, e.Message)
+	bs := []byte(w.formatter.Format(e) + "\n")
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.file.Write(bs)
 }
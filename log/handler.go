@@ -0,0 +1,70 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/best4tires/kit/log/entry"
+)
+
+type levelState struct {
+	Level      entry.Level            `json:"level"`
+	Components map[string]entry.Level `json:"components"`
+}
+
+// LevelHandler returns a http handler to inspect and change the levels of the default logger at runtime (see Logger.LevelHandler)
+func LevelHandler() http.Handler {
+	return std.LevelHandler()
+}
+
+// LevelHandler returns a http handler to inspect and change the levels of the logger at runtime.
+// GET returns the current levels as JSON.
+// PUT and POST change levels with the query parameters "level" and optionally "component", e.g. "?level=debug&component=db".
+// An empty level together with a component removes the component override.
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			if err := l.changeLevel(r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			http.Error(w, fmt.Sprintf("method %q not allowed", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(levelState{
+			Level:      l.Level(),
+			Components: l.ComponentLevels(),
+		})
+	})
+}
+
+func (l *Logger) changeLevel(r *http.Request) error {
+	q := r.URL.Query()
+	comp := q.Get("component")
+	ls := q.Get("level")
+	if ls == "" {
+		if comp == "" {
+			return fmt.Errorf("missing parameter \"level\"")
+		}
+		l.ResetComponentLevel(comp)
+		l.Importantf("log-level: reset level of component %q", comp)
+		return nil
+	}
+	lvl, err := entry.ParseLevel(ls)
+	if err != nil {
+		return err
+	}
+	if comp != "" {
+		l.SetComponentLevel(comp, lvl)
+		l.Importantf("log-level: set level of component %q to %s", comp, lvl.Name())
+		return nil
+	}
+	l.SetLevel(lvl)
+	l.Importantf("log-level: set level to %s", lvl.Name())
+	return nil
+}
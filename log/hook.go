@@ -1,64 +1,26 @@
 package log
 
 import (
-	"fmt"
-
 	"github.com/best4tires/kit/log/entry"
 )
 
-// Hook specifies a func, which is called before a log-entry is passed to the next writer - mostly used for amending logs entries with additional information
-type Hook struct {
-	hookFnc func(e entry.Entry) entry.Entry
-}
+// Hook is a child of the default Logger, which amends log entries with additional information.
+// It's kept as an alias of Logger for compatibility.
+type Hook = Logger
 
-// NewHook creates a new hook with the passed hook function
+// NewHook creates a new child of the default Logger with the passed hook function, which is called before a log-entry is passed to the installed sink
 func NewHook(hookFnc func(e entry.Entry) entry.Entry) *Hook {
-	return &Hook{
-		hookFnc: hookFnc,
-	}
-}
-
-// Debugf logs a debug entry
-func (lh *Hook) Debugf(s string, args ...interface{}) {
-	e := entry.Make(entry.LevelDebug, s, args...)
-	logger.Log(lh.hookFnc(e))
-}
-
-// Infof logs an info entry
-func (lh *Hook) Infof(s string, args ...interface{}) {
-	e := entry.Make(entry.LevelInfo, s, args...)
-	logger.Log(lh.hookFnc(e))
-}
-
-// Warnf logs a warn entry
-func (lh *Hook) Warnf(s string, args ...interface{}) {
-	e := entry.Make(entry.LevelWarn, s, args...)
-	logger.Log(lh.hookFnc(e))
-}
-
-// Errorf logs an error entry
-func (lh *Hook) Errorf(s string, args ...interface{}) {
-	e := entry.Make(entry.LevelError, s, args...)
-	logger.Log(lh.hookFnc(e))
-}
-
-// Fatalf logs a fatla entry and panics
-func (lh *Hook) Fatalf(s string, args ...interface{}) {
-	e := entry.Make(entry.LevelFatal, s, args...)
-	logger.Log(lh.hookFnc(e))
-	panic(fmt.Sprintf(s, args...))
+	return std.WithHook(hookFnc)
 }
 
-// Importantf logs an important entry
-func (lh *Hook) Importantf(s string, args ...interface{}) {
-	e := entry.Make(entry.LevelImportant, s, args...)
-	logger.Log(lh.hookFnc(e))
+// With creates a new child of the default Logger, which amends log entries with the passed key/value pairs
+func With(kvs ...any) *Logger {
+	return std.With(kvs...)
 }
 
-// Accessf logs an access entry
-func (lh *Hook) Accessf(s string, args ...interface{}) {
-	e := entry.Make(entry.LevelAccess, s, args...)
-	logger.Log(lh.hookFnc(e))
+// Named creates a new child of the default Logger, which sets the component of log entries
+func Named(name string) *Logger {
+	return std.Named(name)
 }
 
 // ComponentHook creates a new hook, which modifies the component field of a log entry
@@ -0,0 +1,171 @@
+// Package journald provides a log.Writer sending entries to systemd-journald using its native protocol
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/log/syslog"
+)
+
+// DefaultSocket is the path of journald's native protocol socket
+const DefaultSocket = "/run/systemd/journal/socket"
+
+// Option is the journald-writer option type
+type Option func(w *Writer) error
+
+// WithSocket defines the path of the journald socket
+func WithSocket(path string) Option {
+	return func(w *Writer) error {
+		w.socket = path
+		return nil
+	}
+}
+
+// WithIdentifier defines the SYSLOG_IDENTIFIER of entries without a program
+func WithIdentifier(id string) Option {
+	return func(w *Writer) error {
+		w.identifier = id
+		return nil
+	}
+}
+
+// WithErrHandler defines a handler for write errors. Errors are printed to stderr by default.
+func WithErrHandler(h func(err error)) Option {
+	return func(w *Writer) error {
+		w.onErr = h
+		return nil
+	}
+}
+
+// Available reports, if the journald socket exists, which is the case when running as a systemd unit
+func Available() bool {
+	_, err := os.Stat(DefaultSocket)
+	return err == nil
+}
+
+// Writer implements the log.Writer interface.
+// Each entry is sent as a single datagram; fields are sent as journal fields with sanitized upper-case names.
+type Writer struct {
+	socket     string
+	identifier string
+	onErr      func(error)
+	mu         sync.Mutex
+	conn       *net.UnixConn
+	closed     bool
+}
+
+// NewWriter creates a new journald.Writer
+func NewWriter(opts ...Option) (*Writer, error) {
+	w := &Writer{
+		socket:     DefaultSocket,
+		identifier: filepath.Base(os.Args[0]),
+		onErr: func(err error) {
+			fmt.Fprintf(os.Stderr, "journald-writer: %v\n", err)
+		},
+	}
+	for _, opt := range opts {
+		if err := opt(w); err != nil {
+			return nil, err
+		}
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: w.socket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("dial %q: %w", w.socket, err)
+	}
+	w.conn = conn
+	return w, nil
+}
+
+// Close closes the connection
+func (w *Writer) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
+	w.conn.Close()
+}
+
+// Write sends an entry. Entries written after Close are discarded.
+func (w *Writer) Write(e entry.Entry) {
+	bs := w.encode(e)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	// entries exceeding the socket's datagram size would require passing a memfd, which is not supported
+	if _, err := w.conn.Write(bs); err != nil {
+		w.onErr(fmt.Errorf("write: %w", err))
+	}
+}
+
+func (w *Writer) encode(e entry.Entry) []byte {
+	identifier := e.Program
+	if identifier == "" {
+		identifier = w.identifier
+	}
+	buf := &bytes.Buffer{}
+	writeField(buf, "MESSAGE", e.Message)
+	writeField(buf, "PRIORITY", strconv.Itoa(int(syslog.SeverityOf(e.Level))))
+	writeField(buf, "SYSLOG_IDENTIFIER", identifier)
+	writeField(buf, "LEVEL", e.Level.Name())
+	if e.Component != "" {
+		writeField(buf, "COMPONENT", e.Component)
+	}
+	if e.Caller != nil {
+		writeField(buf, "CODE_FILE", e.Caller.File)
+		writeField(buf, "CODE_LINE", strconv.Itoa(e.Caller.Line))
+		writeField(buf, "CODE_FUNC", e.Caller.Function)
+	}
+	for _, f := range e.Fields {
+		name := fieldName(f.Key)
+		if name == "" {
+			continue
+		}
+		writeField(buf, name, fmt.Sprintf("%v", f.Value))
+	}
+	return buf.Bytes()
+}
+
+// writeField writes a field in the native protocol. Values containing newlines are written with an explicit length.
+func writeField(buf *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(name + "=" + value + "\n")
+		return
+	}
+	buf.WriteString(name + "\n")
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value + "\n")
+}
+
+// fieldName converts a key to a valid journal field name: upper-case letters, digits and underscores, not starting with
+// an underscore or digit and at most 64 characters
+func fieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	name = strings.TrimLeft(name, "_0123456789")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
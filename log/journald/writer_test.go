@@ -0,0 +1,71 @@
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/testutil"
+)
+
+// parse decodes a native protocol datagram
+func parse(t *testing.T, bs []byte) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for len(bs) > 0 {
+		nl := bytes.IndexByte(bs, '\n')
+		if nl < 0 {
+			t.Fatalf("missing newline in %q", bs)
+		}
+		line := bs[:nl]
+		if eq := bytes.IndexByte(line, '='); eq >= 0 {
+			fields[string(line[:eq])] = string(line[eq+1:])
+			bs = bs[nl+1:]
+			continue
+		}
+		bs = bs[nl+1:]
+		n := binary.LittleEndian.Uint64(bs[:8])
+		fields[string(line)] = string(bs[8 : 8+n])
+		bs = bs[8+n+1:]
+	}
+	return fields
+}
+
+func TestWriter(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	testutil.AssertNoErr(t, err, "listen")
+	defer conn.Close()
+
+	w, err := NewWriter(WithSocket(addr), WithIdentifier("ident"))
+	testutil.AssertNoErr(t, err, "new-writer")
+	defer w.Close()
+
+	w.Write(entry.Entry{
+		Time:      time.Now(),
+		Level:     entry.LevelImportant,
+		Component: "comp",
+		Message:   "line 1\nline 2",
+		Fields:    entry.MakeFields("order-id", 42, "_private", "x", "9lives", true),
+	})
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	testutil.AssertNoErr(t, err, "read")
+
+	testutil.AssertEqual(t, map[string]string{
+		"MESSAGE":           "line 1\nline 2",
+		"PRIORITY":          "5",
+		"SYSLOG_IDENTIFIER": "ident",
+		"LEVEL":             "IMPORTANT",
+		"COMPONENT":         "comp",
+		"ORDER_ID":          "42",
+		"PRIVATE":           "x",
+		"LIVES":             "true",
+	}, parse(t, buf[:n]))
+}
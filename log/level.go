@@ -0,0 +1,112 @@
+package log
+
+import (
+	"sync"
+
+	"github.com/best4tires/kit/log/entry"
+)
+
+// levelSet holds a minimum level and per-component overrides
+type levelSet struct {
+	mu    sync.RWMutex
+	min   entry.Level
+	comps map[string]entry.Level
+	// floor is the lowest level of min and all overrides. Levels below floor can be discarded before an entry is made.
+	floor entry.Level
+}
+
+func newLevelSet(min entry.Level) *levelSet {
+	return &levelSet{
+		min:   min,
+		comps: map[string]entry.Level{},
+		floor: min,
+	}
+}
+
+func (ls *levelSet) updateFloor() {
+	ls.floor = ls.min
+	for _, l := range ls.comps {
+		if l < ls.floor {
+			ls.floor = l
+		}
+	}
+}
+
+func (ls *levelSet) set(l entry.Level) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.min = l
+	ls.updateFloor()
+}
+
+func (ls *levelSet) get() entry.Level {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	return ls.min
+}
+
+func (ls *levelSet) setComponent(comp string, l entry.Level) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.comps[comp] = l
+	ls.updateFloor()
+}
+
+func (ls *levelSet) resetComponent(comp string) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	delete(ls.comps, comp)
+	ls.updateFloor()
+}
+
+func (ls *levelSet) components() map[string]entry.Level {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	cs := make(map[string]entry.Level, len(ls.comps))
+	for c, l := range ls.comps {
+		cs[c] = l
+	}
+	return cs
+}
+
+// mayEnable reports, if an entry of level l may pass for any component
+func (ls *levelSet) mayEnable(l entry.Level) bool {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	return l >= ls.floor
+}
+
+// enabled reports, if an entry of level l and component comp passes
+func (ls *levelSet) enabled(l entry.Level, comp string) bool {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	if cl, ok := ls.comps[comp]; ok {
+		return l >= cl
+	}
+	return l >= ls.min
+}
+
+// SetLevel sets the minimum level of the default logger. Entries below that level are discarded.
+func SetLevel(l entry.Level) {
+	std.SetLevel(l)
+}
+
+// Level returns the minimum level of the default logger
+func Level() entry.Level {
+	return std.Level()
+}
+
+// SetComponentLevel overrides the minimum level for entries of the passed component (see ComponentHook and Logger.Named)
+func SetComponentLevel(comp string, l entry.Level) {
+	std.SetComponentLevel(comp, l)
+}
+
+// ResetComponentLevel removes the level override for the passed component
+func ResetComponentLevel(comp string) {
+	std.ResetComponentLevel(comp)
+}
+
+// ComponentLevels returns all component level overrides of the default logger
+func ComponentLevels() map[string]entry.Level {
+	return std.ComponentLevels()
+}
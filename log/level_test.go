@@ -0,0 +1,92 @@
+package log
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/testutil"
+)
+
+type recordWriter struct {
+	mu      sync.Mutex
+	entries []entry.Entry
+}
+
+func (w *recordWriter) Write(e entry.Entry) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.entries = append(w.entries, e)
+}
+
+func (w *recordWriter) Close() {}
+
+func (w *recordWriter) messages() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var ms []string
+	for _, e := range w.entries {
+		ms = append(ms, e.Message)
+	}
+	return ms
+}
+
+func installRecorder(t *testing.T) *recordWriter {
+	rw := &recordWriter{}
+	prev := Installed()
+	Install(NewDefaultLogger("test", rw))
+	t.Cleanup(func() {
+		Install(prev)
+		std.core.levels = newLevelSet(entry.LevelDebug)
+	})
+	return rw
+}
+
+func TestLevelThreshold(t *testing.T) {
+	rw := installRecorder(t)
+
+	SetLevel(entry.LevelWarn)
+	SetComponentLevel("db", entry.LevelDebug)
+	testutil.AssertEqual(t, entry.LevelWarn, Level())
+
+	Debugf("debug")
+	Infof("info")
+	Warnf("warn")
+	Errorf("error")
+	ComponentHook("db").Debugf("db-debug")
+	ComponentHook("http").Infof("http-info")
+
+	ResetComponentLevel("db")
+	ComponentHook("db").Debugf("db-debug-after-reset")
+
+	testutil.AssertEqual(t, []string{"warn", "error", "db-debug"}, rw.messages())
+}
+
+func TestLevelHandler(t *testing.T) {
+	installRecorder(t)
+	h := LevelHandler()
+
+	tests := []struct {
+		method string
+		query  string
+		status int
+		level  entry.Level
+		comps  map[string]entry.Level
+	}{
+		{method: http.MethodGet, status: http.StatusOK, level: entry.LevelDebug, comps: map[string]entry.Level{}},
+		{method: http.MethodPut, query: "level=warn", status: http.StatusOK, level: entry.LevelWarn, comps: map[string]entry.Level{}},
+		{method: http.MethodPut, query: "level=info&component=db", status: http.StatusOK, level: entry.LevelWarn, comps: map[string]entry.Level{"db": entry.LevelInfo}},
+		{method: http.MethodPut, query: "level=loud", status: http.StatusBadRequest, level: entry.LevelWarn, comps: map[string]entry.Level{"db": entry.LevelInfo}},
+		{method: http.MethodPost, query: "component=db", status: http.StatusOK, level: entry.LevelWarn, comps: map[string]entry.Level{}},
+		{method: http.MethodDelete, status: http.StatusMethodNotAllowed, level: entry.LevelWarn, comps: map[string]entry.Level{}},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(test.method, "/log/level?"+test.query, nil))
+		testutil.AssertEqual(t, test.status, rec.Code)
+		testutil.AssertEqual(t, test.level, Level())
+		testutil.AssertEqual(t, test.comps, ComponentLevels())
+	}
+}
@@ -2,8 +2,6 @@ package log
 
 import (
 	"fmt"
-	"runtime/debug"
-	"strings"
 
 	"github.com/best4tires/kit/log/console"
 	"github.com/best4tires/kit/log/entry"
@@ -11,60 +9,73 @@ import (
 
 // Debugf logs a debug entry to the installed logger
 func Debugf(s string, args ...interface{}) {
-	logger.Log(entry.Make(entry.LevelDebug, s, args...))
+	std.logf(entry.LevelDebug, s, args)
 }
 
 // Infof logs an info entry to the installed logger
 func Infof(s string, args ...interface{}) {
-	logger.Log(entry.Make(entry.LevelInfo, s, args...))
+	std.logf(entry.LevelInfo, s, args)
 }
 
 // Warnf logs a warn entry to the installed logger
 func Warnf(s string, args ...interface{}) {
-	logger.Log(entry.Make(entry.LevelWarn, s, args...))
+	std.logf(entry.LevelWarn, s, args)
 }
 
 // Errorf logs an error entry to the installed logger
 func Errorf(s string, args ...interface{}) {
-	logger.Log(entry.Make(entry.LevelError, s, args...))
+	std.logf(entry.LevelError, s, args)
 }
 
 // Fatalf logs a fatal entry to the installed logger and panics
 func Fatalf(s string, args ...interface{}) {
-	logger.Log(entry.Make(entry.LevelFatal, s, args...))
+	std.logf(entry.LevelFatal, s, args)
 	panic(fmt.Sprintf(s, args...))
 }
 
-// Warnf logs a warn entry to the installed logger
+// Importantf logs an important entry to the installed logger
 func Importantf(s string, args ...interface{}) {
-	logger.Log(entry.Make(entry.LevelImportant, s, args...))
+	std.logf(entry.LevelImportant, s, args)
 }
 
 // Accessf logs a access entry to the installed logger
 func Accessf(s string, args ...interface{}) {
-	logger.Log(entry.Make(entry.LevelAccess, s, args...))
+	std.logf(entry.LevelAccess, s, args)
 }
 
-// Logger defines the general logger interface
-type Logger interface {
+// Sink defines the general interface of the target a Logger passes its entries to
+type Sink interface {
 	Log(e entry.Entry)
 	Close()
 }
 
-var logger Logger = NewDefaultLogger("default", console.NewWriter())
+// std is the default Logger used by the package-level functions
+var std = New(NewDefaultLogger("default", console.NewWriter()))
+
+// Default returns the default Logger, which is used by the package-level functions and writes to the installed sink
+func Default() *Logger {
+	return std
+}
+
+// Install installs the provided Sink as the global sink of the default Logger. It's safe to be called concurrently with logging.
+func Install(s Sink) {
+	std.core.setSink(s)
+}
 
-// Install installs the provided Logger as the global logger
-func Install(l Logger) {
-	logger = l
+// Installed returns the installed sink of the default Logger
+func Installed() Sink {
+	return std.core.getSink()
 }
 
+// Close closes the installed sink
 func Close() {
-	logger.Close()
+	std.Close()
 }
 
+// DebugStack logs the stack of the caller as a single debug entry
 func DebugStack() {
-	dbs := debug.Stack()
-	for i, l := range strings.Split(string(dbs), "\n")[7:] {
-		Debugf("stack %03d: %s", i, l)
-	}
+	stack := Stack(1)
+	std.WithHook(func(e entry.Entry) entry.Entry {
+		return e.WithFields(entry.F(FieldStack, stack))
+	}).logf(entry.LevelDebug, "stack", nil)
 }
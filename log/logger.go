@@ -0,0 +1,195 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/best4tires/kit/log/entry"
+)
+
+// core holds the state shared by a logger and all its children
+type core struct {
+	sink         atomic.Value // sinkHolder
+	levels       *levelSet
+	reportCaller atomic.Bool
+}
+
+// sinkHolder wraps a Sink, since atomic.Value requires a consistent concrete type
+type sinkHolder struct {
+	Sink
+}
+
+func (c *core) getSink() Sink {
+	return c.sink.Load().(sinkHolder).Sink
+}
+
+func (c *core) setSink(s Sink) {
+	c.sink.Store(sinkHolder{s})
+}
+
+// Logger logs entries to a Sink. Children created by With, Named, Err and the like share the sink, the levels and
+// the caller reporting of their parent and amend entries with additional information.
+type Logger struct {
+	core    *core
+	hookFnc func(e entry.Entry) entry.Entry
+}
+
+// New creates a new Logger logging to the passed sink
+func New(sink Sink) *Logger {
+	c := &core{
+		levels: newLevelSet(entry.LevelDebug),
+	}
+	c.setSink(sink)
+	return &Logger{
+		core: c,
+	}
+}
+
+// NewWithWriter creates a new Logger for the named program logging to the passed writer
+func NewWithWriter(name string, w Writer) *Logger {
+	return New(NewDefaultLogger(name, w))
+}
+
+// logf makes an entry, applies the hook function and passes it to the sink, if the entry's level is enabled
+func (l *Logger) logf(level entry.Level, s string, args []interface{}) {
+	if !l.core.levels.mayEnable(level) {
+		return
+	}
+	e := entry.Make(level, s, args...)
+	if l.core.reportCaller.Load() {
+		// skip logf and the exported log function
+		e.Caller = entry.CallerAt(2)
+	}
+	if l.hookFnc != nil {
+		e = l.hookFnc(e)
+	}
+	if !l.core.levels.enabled(e.Level, e.Component) {
+		return
+	}
+	switch s := l.core.getSink().(type) {
+	case *DefaultLogger:
+		s.log(e)
+	default:
+		s.Log(e)
+	}
+}
+
+// Debugf logs a debug entry
+func (l *Logger) Debugf(s string, args ...interface{}) {
+	l.logf(entry.LevelDebug, s, args)
+}
+
+// Infof logs an info entry
+func (l *Logger) Infof(s string, args ...interface{}) {
+	l.logf(entry.LevelInfo, s, args)
+}
+
+// Warnf logs a warn entry
+func (l *Logger) Warnf(s string, args ...interface{}) {
+	l.logf(entry.LevelWarn, s, args)
+}
+
+// Errorf logs an error entry
+func (l *Logger) Errorf(s string, args ...interface{}) {
+	l.logf(entry.LevelError, s, args)
+}
+
+// Fatalf logs a fatal entry and panics
+func (l *Logger) Fatalf(s string, args ...interface{}) {
+	l.logf(entry.LevelFatal, s, args)
+	panic(fmt.Sprintf(s, args...))
+}
+
+// Importantf logs an important entry
+func (l *Logger) Importantf(s string, args ...interface{}) {
+	l.logf(entry.LevelImportant, s, args)
+}
+
+// Accessf logs an access entry
+func (l *Logger) Accessf(s string, args ...interface{}) {
+	l.logf(entry.LevelAccess, s, args)
+}
+
+// WithHook returns a child logger, which applies the passed hook function after the hook functions of its parent
+func (l *Logger) WithHook(hookFnc func(e entry.Entry) entry.Entry) *Logger {
+	parent := l.hookFnc
+	if parent == nil {
+		return &Logger{core: l.core, hookFnc: hookFnc}
+	}
+	return &Logger{
+		core: l.core,
+		hookFnc: func(e entry.Entry) entry.Entry {
+			return hookFnc(parent(e))
+		},
+	}
+}
+
+// With returns a child logger, which amends entries with the passed key/value pairs
+func (l *Logger) With(kvs ...any) *Logger {
+	fs := entry.MakeFields(kvs...)
+	return l.WithHook(func(e entry.Entry) entry.Entry {
+		return e.WithFields(fs...)
+	})
+}
+
+// Named returns a child logger, which sets the component of entries. The name is appended to the component of
+// the parent separated by a dot, e.g. "db.pool".
+func (l *Logger) Named(name string) *Logger {
+	return l.WithHook(func(e entry.Entry) entry.Entry {
+		if e.Component == "" {
+			e.Component = name
+		} else {
+			e.Component += "." + name
+		}
+		return e
+	})
+}
+
+// FromContext returns a child logger, which amends entries with the fields carried by ctx (see WithContext)
+func (l *Logger) FromContext(ctx context.Context) *Logger {
+	fs := ContextFields(ctx)
+	return l.WithHook(func(e entry.Entry) entry.Entry {
+		return e.WithFields(fs...)
+	})
+}
+
+// SetLevel sets the minimum level. Entries below that level are discarded.
+func (l *Logger) SetLevel(lvl entry.Level) {
+	l.core.levels.set(lvl)
+}
+
+// Level returns the minimum level
+func (l *Logger) Level() entry.Level {
+	return l.core.levels.get()
+}
+
+// SetComponentLevel overrides the minimum level for entries of the passed component
+func (l *Logger) SetComponentLevel(comp string, lvl entry.Level) {
+	l.core.levels.setComponent(comp, lvl)
+}
+
+// ResetComponentLevel removes the level override for the passed component
+func (l *Logger) ResetComponentLevel(comp string) {
+	l.core.levels.resetComponent(comp)
+}
+
+// ComponentLevels returns all component level overrides
+func (l *Logger) ComponentLevels() map[string]entry.Level {
+	return l.core.levels.components()
+}
+
+// SetReportCaller enables or disables recording the caller's file, line and function on each entry
+func (l *Logger) SetReportCaller(on bool) {
+	l.core.reportCaller.Store(on)
+}
+
+// ReportCaller returns, if caller reporting is enabled
+func (l *Logger) ReportCaller() bool {
+	return l.core.reportCaller.Load()
+}
+
+// Close closes the sink of the logger
+func (l *Logger) Close() {
+	l.core.getSink().Close()
+}
@@ -0,0 +1,55 @@
+package log
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestLoggerInstances(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		i := i
+		t.Run(fmt.Sprintf("instance_%d", i), func(t *testing.T) {
+			t.Parallel()
+			rw := &recordWriter{}
+			l := NewWithWriter(fmt.Sprintf("prog-%d", i), rw)
+			l.SetLevel(entry.LevelInfo)
+
+			l.Debugf("debug %d", i)
+			l.Infof("info %d", i)
+			l.Named("db").Named("pool").With("conn", i).Warnf("warn %d", i)
+
+			testutil.AssertEqual(t, []string{fmt.Sprintf("info %d", i), fmt.Sprintf("warn %d", i)}, rw.messages())
+			e := rw.entries[1]
+			testutil.AssertEqual(t, fmt.Sprintf("prog-%d", i), e.Program)
+			testutil.AssertEqual(t, "db.pool", e.Component)
+			testutil.AssertEqual(t, entry.MakeFields("conn", i), e.Fields)
+		})
+	}
+}
+
+func TestLoggerChildrenShareState(t *testing.T) {
+	rw := &recordWriter{}
+	l := NewWithWriter("prog", rw)
+	child := l.Named("db")
+
+	l.SetComponentLevel("db", entry.LevelError)
+	child.Warnf("suppressed")
+	l.Warnf("root")
+	child.SetLevel(entry.LevelError)
+	l.Warnf("suppressed too")
+
+	testutil.AssertEqual(t, []string{"root"}, rw.messages())
+	testutil.AssertEqual(t, entry.LevelError, l.Level())
+}
+
+func TestHookFollowsInstall(t *testing.T) {
+	h := ComponentHook("comp")
+	rw := installRecorder(t)
+	h.Infof("after install")
+
+	testutil.AssertEqual(t, []string{"after install"}, rw.messages())
+	testutil.AssertEqual(t, "comp", rw.entries[0].Component)
+}
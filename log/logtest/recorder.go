@@ -0,0 +1,128 @@
+// Package logtest provides a log.Writer recording entries in memory for assertions in tests
+package logtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/best4tires/kit/log"
+	"github.com/best4tires/kit/log/console"
+	"github.com/best4tires/kit/log/entry"
+)
+
+// Recorder implements the log.Writer interface.
+// It records all entries and routes them to t.Log, so they only show up for failing tests or with -v.
+type Recorder struct {
+	t       testing.TB
+	mu      sync.Mutex
+	entries []entry.Entry
+	done    bool
+}
+
+// NewRecorder creates a new Recorder for the passed test
+func NewRecorder(t testing.TB) *Recorder {
+	r := &Recorder{t: t}
+	t.Cleanup(func() {
+		// t.Log must not be called after the test has completed
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.done = true
+	})
+	return r
+}
+
+// Install installs a recorder as sink of the default logger for the duration of the test.
+// The previous sink and level are restored, when the test completes.
+func Install(t testing.TB) *Recorder {
+	r := NewRecorder(t)
+	prevSink := log.Installed()
+	prevLevel := log.Level()
+	log.Install(log.NewDefaultLogger(t.Name(), r))
+	log.SetLevel(entry.LevelDebug)
+	t.Cleanup(func() {
+		log.Install(prevSink)
+		log.SetLevel(prevLevel)
+	})
+	return r
+}
+
+// New creates a new logger recording to its own recorder, which is useful for parallel tests
+func New(t testing.TB) (*log.Logger, *Recorder) {
+	r := NewRecorder(t)
+	return log.NewWithWriter(t.Name(), r), r
+}
+
+// Write records an entry
+func (r *Recorder) Write(e entry.Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, e)
+	if !r.done {
+		r.t.Log(console.TextFormatter{}.Format(e))
+	}
+}
+
+// Close does nothing, the entries remain available
+func (r *Recorder) Close() {}
+
+// Entries returns all recorded entries
+func (r *Recorder) Entries() []entry.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	es := make([]entry.Entry, len(r.entries))
+	copy(es, r.entries)
+	return es
+}
+
+// Reset discards all recorded entries
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+// Find returns the first recorded entry matching the passed func
+func (r *Recorder) Find(match func(e entry.Entry) bool) (entry.Entry, bool) {
+	for _, e := range r.Entries() {
+		if match(e) {
+			return e, true
+		}
+	}
+	return entry.Entry{}, false
+}
+
+// Contains reports, if an entry of the passed level has been recorded, whose message contains substr
+func (r *Recorder) Contains(level entry.Level, substr string) bool {
+	_, ok := r.Find(func(e entry.Entry) bool {
+		return e.Level == level && strings.Contains(e.Message, substr)
+	})
+	return ok
+}
+
+// AssertLogged fails the test, if no entry of the passed level has been recorded, whose message contains substr
+func (r *Recorder) AssertLogged(level entry.Level, substr string) {
+	r.t.Helper()
+	if !r.Contains(level, substr) {
+		r.t.Fatalf("assert-logged: no %s entry containing %q", level.Name(), substr)
+	}
+}
+
+// AssertNotLogged fails the test, if an entry of the passed level has been recorded, whose message contains substr
+func (r *Recorder) AssertNotLogged(level entry.Level, substr string) {
+	r.t.Helper()
+	if r.Contains(level, substr) {
+		r.t.Fatalf("assert-not-logged: found %s entry containing %q", level.Name(), substr)
+	}
+}
+
+// AssertNoneAtOrAbove fails the test, if an entry of the passed level or above has been recorded
+func (r *Recorder) AssertNoneAtOrAbove(level entry.Level) {
+	r.t.Helper()
+	e, ok := r.Find(func(e entry.Entry) bool {
+		return e.Level >= level
+	})
+	if ok {
+		r.t.Fatalf("assert-none-at-or-above %s: found %s entry %q", level.Name(), e.Level.Name(), e.Message)
+	}
+}
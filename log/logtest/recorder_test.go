@@ -0,0 +1,41 @@
+package logtest
+
+import (
+	"testing"
+
+	"github.com/best4tires/kit/log"
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestInstall(t *testing.T) {
+	prev := log.Installed()
+	t.Run("installed", func(t *testing.T) {
+		r := Install(t)
+		log.Infof("hello %s", "world")
+		log.Named("db").Errorf("connection lost")
+
+		r.AssertLogged(entry.LevelInfo, "hello world")
+		r.AssertLogged(entry.LevelError, "connection")
+		r.AssertNotLogged(entry.LevelWarn, "connection")
+		testutil.AssertEqual(t, 2, len(r.Entries()))
+
+		r.Reset()
+		log.Debugf("debug")
+		r.AssertNoneAtOrAbove(entry.LevelWarn)
+	})
+	if log.Installed() != prev {
+		t.Fatalf("previous sink not restored")
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	l, r := New(t)
+	l.With("k", "v").Warnf("warned")
+
+	e, ok := r.Find(func(e entry.Entry) bool { return e.Level == entry.LevelWarn })
+	testutil.AssertEqual(t, true, ok)
+	testutil.AssertEqual(t, entry.MakeFields("k", "v"), e.Fields)
+	testutil.AssertEqual(t, t.Name(), e.Program)
+}
@@ -1,11 +1,17 @@
 package log
 
-import "github.com/best4tires/kit/log/entry"
+import (
+	"sync"
+
+	"github.com/best4tires/kit/log/entry"
+)
 
 // MultiWriter implements the Writer interface
 // it provides a logger middleware which writes to multiple next writers
 type MultiWriter struct {
 	targets []Writer
+	mu      sync.RWMutex
+	closed  bool
 }
 
 // NewMultiWriter creates a new MultiWriter
@@ -15,15 +21,26 @@ func NewMultiWriter(targets ...Writer) *MultiWriter {
 	}
 }
 
-// Close closes the writer and all related resources
+// Close closes the writer and all related resources. Targets are closed only once.
 func (mw *MultiWriter) Close() {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	if mw.closed {
+		return
+	}
+	mw.closed = true
 	for _, t := range mw.targets {
 		t.Close()
 	}
 }
 
-// Write writes an entry to all installed targets
+// Write writes an entry to all installed targets. Entries written after Close are discarded.
 func (mw *MultiWriter) Write(e entry.Entry) {
+	mw.mu.RLock()
+	defer mw.mu.RUnlock()
+	if mw.closed {
+		return
+	}
 	for _, t := range mw.targets {
 		t.Write(e)
 	}
@@ -0,0 +1,422 @@
+// Package otlp provides a log.Writer exporting entries with the OTLP/HTTP JSON protocol
+package otlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/best4tires/kit/log"
+	"github.com/best4tires/kit/log/entry"
+)
+
+const scopeName = "github.com/best4tires/kit/log"
+
+// Option is the otlp-writer option type
+type Option func(w *Writer) error
+
+// WithServiceName defines the service.name resource attribute. It defaults to the program of each entry.
+func WithServiceName(name string) Option {
+	return func(w *Writer) error {
+		w.serviceName = name
+		return nil
+	}
+}
+
+// WithHeader adds a header to each export request, e.g. for authorization
+func WithHeader(key, value string) Option {
+	return func(w *Writer) error {
+		w.headers.Add(key, value)
+		return nil
+	}
+}
+
+// WithBatchSize defines the number of entries, which triggers an export. It defaults to 512.
+func WithBatchSize(n int) Option {
+	return func(w *Writer) error {
+		if n < 1 {
+			return fmt.Errorf("invalid batch-size: %d", n)
+		}
+		w.batchSize = n
+		return nil
+	}
+}
+
+// WithMaxQueueSize defines the number of buffered entries, above which new entries are dropped. It defaults to 10000.
+func WithMaxQueueSize(n int) Option {
+	return func(w *Writer) error {
+		if n < 1 {
+			return fmt.Errorf("invalid max-queue-size: %d", n)
+		}
+		w.maxQueueSize = n
+		return nil
+	}
+}
+
+// WithFlushInterval defines the interval, after which buffered entries are exported. It defaults to 5 seconds.
+func WithFlushInterval(d time.Duration) Option {
+	return func(w *Writer) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid flush-interval: %s", d)
+		}
+		w.flushInterval = d
+		return nil
+	}
+}
+
+// WithHTTPClient defines the client used for export requests
+func WithHTTPClient(clt *http.Client) Option {
+	return func(w *Writer) error {
+		w.client = clt
+		return nil
+	}
+}
+
+// WithErrHandler defines a handler for export errors. Errors are printed to stderr by default.
+func WithErrHandler(h func(err error)) Option {
+	return func(w *Writer) error {
+		w.onErr = h
+		return nil
+	}
+}
+
+// Stats holds the counters of a Writer
+type Stats struct {
+	Exported uint64
+	Dropped  uint64
+}
+
+// Writer implements the log.Writer interface.
+// Entries are buffered and exported in batches to an OTLP/HTTP endpoint like "http://localhost:4318/v1/logs".
+// The trace_id and span_id fields (see log.FieldTraceID) are exported as the record's trace context.
+type Writer struct {
+	endpoint      string
+	serviceName   string
+	headers       http.Header
+	batchSize     int
+	maxQueueSize  int
+	flushInterval time.Duration
+	client        *http.Client
+	onErr         func(error)
+	mu            sync.Mutex
+	queue         []entry.Entry
+	closed        bool
+	overflow      bool
+	exported      atomic.Uint64
+	dropped       atomic.Uint64
+	flushC        chan struct{}
+	stopC         chan struct{}
+	doneC         chan struct{}
+}
+
+// NewWriter creates a new otlp.Writer exporting to endpoint
+func NewWriter(endpoint string, opts ...Option) (*Writer, error) {
+	w := &Writer{
+		endpoint:      endpoint,
+		headers:       http.Header{},
+		batchSize:     512,
+		maxQueueSize:  10000,
+		flushInterval: 5 * time.Second,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		onErr: func(err error) {
+			fmt.Fprintf(os.Stderr, "otlp-writer: %v\n", err)
+		},
+		flushC: make(chan struct{}, 1),
+		stopC:  make(chan struct{}),
+		doneC:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(w); err != nil {
+			return nil, err
+		}
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *Writer) run() {
+	defer close(w.doneC)
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stopC:
+			w.flush()
+			return
+		case <-ticker.C:
+			w.flush()
+		case <-w.flushC:
+			w.flush()
+		}
+	}
+}
+
+// Write buffers an entry for export. Entries exceeding the max queue size or written after Close are dropped.
+// The first dropped entry of an overflow is reported to the error handler.
+func (w *Writer) Write(e entry.Entry) {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		w.dropped.Add(1)
+		return
+	}
+	if len(w.queue) >= w.maxQueueSize {
+		first := !w.overflow
+		w.overflow = true
+		w.mu.Unlock()
+		w.dropped.Add(1)
+		if first {
+			w.onErr(fmt.Errorf("queue exceeds %d entries, dropping entries", w.maxQueueSize))
+		}
+		return
+	}
+	defer w.mu.Unlock()
+	w.overflow = false
+	w.queue = append(w.queue, e)
+	if len(w.queue) >= w.batchSize {
+		select {
+		case w.flushC <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close exports all buffered entries and stops the writer
+func (w *Writer) Close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	w.mu.Unlock()
+	close(w.stopC)
+	<-w.doneC
+}
+
+// Stats returns the number of exported and dropped entries
+func (w *Writer) Stats() Stats {
+	return Stats{
+		Exported: w.exported.Load(),
+		Dropped:  w.dropped.Load(),
+	}
+}
+
+func (w *Writer) flush() {
+	for {
+		w.mu.Lock()
+		n := len(w.queue)
+		if n > w.batchSize {
+			n = w.batchSize
+		}
+		batch := w.queue[:n:n]
+		w.queue = w.queue[n:]
+		w.mu.Unlock()
+		if len(batch) == 0 {
+			return
+		}
+		if err := w.export(batch); err != nil {
+			w.dropped.Add(uint64(len(batch)))
+			w.onErr(err)
+			continue
+		}
+		w.exported.Add(uint64(len(batch)))
+	}
+}
+
+func (w *Writer) export(es []entry.Entry) error {
+	bs, err := json.Marshal(w.request(es))
+	if err != nil {
+		return fmt.Errorf("json.marshal: %w", err)
+	}
+	r, err := http.NewRequest(http.MethodPost, w.endpoint, bytes.NewReader(bs))
+	if err != nil {
+		return fmt.Errorf("new-request %q: %w", w.endpoint, err)
+	}
+	for k, vs := range w.headers {
+		r.Header[k] = vs
+	}
+	r.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(r)
+	if err != nil {
+		return fmt.Errorf("client.do: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("export %d entries: status-code: %s", len(es), resp.Status)
+	}
+	return nil
+}
+
+// OTLP JSON model, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+
+type exportRequest struct {
+	ResourceLogs []resourceLogs `json:"resourceLogs"`
+}
+
+type resourceLogs struct {
+	Resource  resource    `json:"resource"`
+	ScopeLogs []scopeLogs `json:"scopeLogs"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeLogs struct {
+	Scope      scope       `json:"scope"`
+	LogRecords []logRecord `json:"logRecords"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type logRecord struct {
+	TimeUnixNano         string     `json:"timeUnixNano"`
+	ObservedTimeUnixNano string     `json:"observedTimeUnixNano"`
+	SeverityNumber       int        `json:"severityNumber"`
+	SeverityText         string     `json:"severityText"`
+	Body                 anyValue   `json:"body"`
+	Attributes           []keyValue `json:"attributes,omitempty"`
+	TraceID              string     `json:"traceId,omitempty"`
+	SpanID               string     `json:"spanId,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string     `json:"stringValue,omitempty"`
+	BoolValue   *bool       `json:"boolValue,omitempty"`
+	IntValue    *string     `json:"intValue,omitempty"`
+	DoubleValue *float64    `json:"doubleValue,omitempty"`
+	ArrayValue  *arrayValue `json:"arrayValue,omitempty"`
+}
+
+type arrayValue struct {
+	Values []anyValue `json:"values"`
+}
+
+func stringValue(s string) anyValue {
+	return anyValue{StringValue: &s}
+}
+
+func toAnyValue(v any) anyValue {
+	switch v := v.(type) {
+	case string:
+		return stringValue(v)
+	case bool:
+		return anyValue{BoolValue: &v}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		s := fmt.Sprintf("%d", v)
+		return anyValue{IntValue: &s}
+	case float32:
+		f := float64(v)
+		return anyValue{DoubleValue: &f}
+	case float64:
+		return anyValue{DoubleValue: &v}
+	case []string:
+		av := &arrayValue{}
+		for _, s := range v {
+			av.Values = append(av.Values, stringValue(s))
+		}
+		return anyValue{ArrayValue: av}
+	case error:
+		return stringValue(v.Error())
+	case fmt.Stringer:
+		return stringValue(v.String())
+	default:
+		return stringValue(fmt.Sprintf("%v", v))
+	}
+}
+
+// SeverityNumber maps a log level to the OTLP severity number
+func SeverityNumber(l entry.Level) int {
+	switch l {
+	case entry.LevelDebug:
+		return 5
+	case entry.LevelInfo, entry.LevelAccess:
+		return 9
+	case entry.LevelImportant:
+		return 10
+	case entry.LevelWarn:
+		return 13
+	case entry.LevelError:
+		return 17
+	case entry.LevelFatal:
+		return 21
+	default:
+		return 0
+	}
+}
+
+func (w *Writer) request(es []entry.Entry) exportRequest {
+	observed := strconv.FormatInt(time.Now().UnixNano(), 10)
+	byService := map[string]*resourceLogs{}
+	var services []string
+	for _, e := range es {
+		service := w.serviceName
+		if service == "" {
+			service = e.Program
+		}
+		rl, ok := byService[service]
+		if !ok {
+			rl = &resourceLogs{
+				Resource: resource{
+					Attributes: []keyValue{{Key: "service.name", Value: stringValue(service)}},
+				},
+				ScopeLogs: []scopeLogs{{Scope: scope{Name: scopeName}}},
+			}
+			byService[service] = rl
+			services = append(services, service)
+		}
+		rl.ScopeLogs[0].LogRecords = append(rl.ScopeLogs[0].LogRecords, record(e, observed))
+	}
+	req := exportRequest{}
+	for _, service := range services {
+		req.ResourceLogs = append(req.ResourceLogs, *byService[service])
+	}
+	return req
+}
+
+func record(e entry.Entry, observed string) logRecord {
+	lr := logRecord{
+		TimeUnixNano:         strconv.FormatInt(e.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: observed,
+		SeverityNumber:       SeverityNumber(e.Level),
+		SeverityText:         e.Level.Name(),
+		Body:                 stringValue(e.Message),
+	}
+	if e.Component != "" {
+		lr.Attributes = append(lr.Attributes, keyValue{Key: "component", Value: stringValue(e.Component)})
+	}
+	if e.Caller != nil {
+		lr.Attributes = append(lr.Attributes,
+			keyValue{Key: "code.filepath", Value: stringValue(e.Caller.File)},
+			keyValue{Key: "code.lineno", Value: toAnyValue(e.Caller.Line)},
+			keyValue{Key: "code.function", Value: stringValue(e.Caller.Function)},
+		)
+	}
+	for _, f := range e.Fields {
+		switch f.Key {
+		case log.FieldTraceID:
+			lr.TraceID = fmt.Sprintf("%v", f.Value)
+		case log.FieldSpanID:
+			lr.SpanID = fmt.Sprintf("%v", f.Value)
+		default:
+			lr.Attributes = append(lr.Attributes, keyValue{Key: f.Key, Value: toAnyValue(f.Value)})
+		}
+	}
+	return lr
+}
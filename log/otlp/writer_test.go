@@ -0,0 +1,127 @@
+package otlp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/best4tires/kit/log"
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/testutil"
+)
+
+type receiver struct {
+	mu       sync.Mutex
+	requests []map[string]any
+	auth     []string
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, req)
+	rc.auth = append(rc.auth, r.Header.Get("Authorization"))
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestWriter(t *testing.T) {
+	rc := &receiver{}
+	s := httptest.NewServer(rc)
+	defer s.Close()
+
+	w, err := NewWriter(s.URL+"/v1/logs", WithBatchSize(2), WithFlushInterval(time.Hour), WithHeader("Authorization", "Bearer x"))
+	testutil.AssertNoErr(t, err, "new-writer")
+
+	tm := time.Unix(1680674828, 9)
+	w.Write(entry.Entry{
+		Time:      tm,
+		Level:     entry.LevelWarn,
+		Program:   "orders",
+		Component: "db",
+		Message:   "slow query",
+		Fields: entry.MakeFields(
+			log.FieldTraceID, "4bf92f3577b34da6a3ce929d0e0e4736",
+			log.FieldSpanID, "00f067aa0ba902b7",
+			"rows", 42,
+			"cached", false,
+		),
+	})
+	w.Write(entry.Entry{Time: tm, Level: entry.LevelInfo, Program: "billing", Message: "ok"})
+	w.Write(entry.Entry{Time: tm, Level: entry.LevelError, Program: "billing", Message: "failed"})
+	w.Close()
+	w.Write(entry.Entry{Time: tm, Level: entry.LevelError, Program: "billing", Message: "after close"})
+
+	testutil.AssertEqual(t, 2, len(rc.requests))
+	testutil.AssertEqual(t, []string{"Bearer x", "Bearer x"}, rc.auth)
+	testutil.AssertEqual(t, Stats{Exported: 3, Dropped: 1}, w.Stats())
+
+	rls := rc.requests[0]["resourceLogs"].([]any)
+	testutil.AssertEqual(t, 2, len(rls))
+	rl := rls[0].(map[string]any)
+	testutil.AssertEqual(t, []any{map[string]any{"key": "service.name", "value": map[string]any{"stringValue": "orders"}}},
+		rl["resource"].(map[string]any)["attributes"])
+	record := rl["scopeLogs"].([]any)[0].(map[string]any)["logRecords"].([]any)[0].(map[string]any)
+	delete(record, "observedTimeUnixNano")
+	testutil.AssertEqual(t, map[string]any{
+		"timeUnixNano":   "1680674828000000009",
+		"severityNumber": float64(13),
+		"severityText":   "WARN",
+		"body":           map[string]any{"stringValue": "slow query"},
+		"traceId":        "4bf92f3577b34da6a3ce929d0e0e4736",
+		"spanId":         "00f067aa0ba902b7",
+		"attributes": []any{
+			map[string]any{"key": "component", "value": map[string]any{"stringValue": "db"}},
+			map[string]any{"key": "rows", "value": map[string]any{"intValue": "42"}},
+			map[string]any{"key": "cached", "value": map[string]any{"boolValue": false}},
+		},
+	}, record)
+
+	rls = rc.requests[1]["resourceLogs"].([]any)
+	testutil.AssertEqual(t, 1, len(rls))
+}
+
+func TestWriterOverflow(t *testing.T) {
+	rc := &receiver{}
+	s := httptest.NewServer(rc)
+	defer s.Close()
+
+	var errs []error
+	w, err := NewWriter(s.URL+"/v1/logs", WithMaxQueueSize(2), WithFlushInterval(time.Hour),
+		WithErrHandler(func(err error) { errs = append(errs, err) }))
+	testutil.AssertNoErr(t, err, "new-writer")
+	for i := 0; i < 5; i++ {
+		w.Write(entry.Make(entry.LevelInfo, "m%d", i))
+	}
+	w.Close()
+
+	testutil.AssertEqual(t, Stats{Exported: 2, Dropped: 3}, w.Stats())
+	testutil.AssertEqual(t, 1, len(errs))
+	testutil.AssertEqual(t, "queue exceeds 2 entries, dropping entries", errs[0].Error())
+}
+
+func TestWriterExportError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer s.Close()
+
+	var errs []error
+	w, err := NewWriter(s.URL+"/v1/logs", WithBatchSize(2), WithFlushInterval(time.Hour),
+		WithErrHandler(func(err error) { errs = append(errs, err) }))
+	testutil.AssertNoErr(t, err, "new-writer")
+	for i := 0; i < 3; i++ {
+		w.Write(entry.Make(entry.LevelInfo, "m%d", i))
+	}
+	w.Close()
+
+	testutil.AssertEqual(t, Stats{Exported: 0, Dropped: 3}, w.Stats())
+	testutil.AssertEqual(t, 2, len(errs))
+}
@@ -1,11 +1,19 @@
 package rotate
 
 import (
+	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/best4tires/kit/log/console"
 	"github.com/best4tires/kit/log/entry"
 )
 
@@ -18,6 +26,8 @@ const (
 	GB int = MB * 1024
 )
 
+const gzExt = ".gz"
+
 // Option is the type for a rotate.Writer create option
 type Option func(w *Writer) error
 
@@ -28,6 +38,7 @@ func WithFileSize(s int) Option {
 			return fmt.Errorf("invalid file-size: %d", s)
 		}
 		w.fileSize = s
+		w.fileSizeSet = true
 		return nil
 	}
 }
@@ -39,6 +50,7 @@ func WithFileCount(c int) Option {
 			return fmt.Errorf("invalid file-count: %d", c)
 		}
 		w.fileCount = c
+		w.fileCountSet = true
 		return nil
 	}
 }
@@ -51,40 +63,139 @@ func WithFileOpErrHandler(h func(err error)) Option {
 	}
 }
 
+// WithFormatter defines the formatter used to render entries. It defaults to the console.TextFormatter
+func WithFormatter(f console.Formatter) Option {
+	return func(w *Writer) error {
+		if f == nil {
+			return fmt.Errorf("invalid formatter: nil")
+		}
+		w.formatter = f
+		return nil
+	}
+}
+
+// WithInterval enables time based rotation. Rotated files are named after the period they cover, e.g. "app.log.2023-04-05".
+// With an interval, size and count limits only apply if set explicitly by WithFileSize and WithFileCount.
+func WithInterval(i Interval) Option {
+	return func(w *Writer) error {
+		if i < IntervalNone || i > Daily {
+			return fmt.Errorf("invalid interval: %d", i)
+		}
+		w.interval = i
+		return nil
+	}
+}
+
+// WithCompression enables gzip compression of rotated files
+func WithCompression() Option {
+	return func(w *Writer) error {
+		w.compress = true
+		return nil
+	}
+}
+
+// WithMaxAge defines the maximum age of rotated files before they will be deleted
+func WithMaxAge(d time.Duration) Option {
+	return func(w *Writer) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid max-age: %s", d)
+		}
+		w.maxAge = d
+		return nil
+	}
+}
+
+// WithRotateSignals triggers a rotation, whenever the process receives one of the passed signals, e.g. syscall.SIGHUP
+func WithRotateSignals(sigs ...os.Signal) Option {
+	return func(w *Writer) error {
+		if len(sigs) == 0 {
+			return fmt.Errorf("no signals")
+		}
+		w.signals = sigs
+		return nil
+	}
+}
+
+// Interval defines the period for time based rotation
+type Interval int
+
+const (
+	// IntervalNone disables time based rotation
+	IntervalNone Interval = iota
+	// Hourly rotates at the beginning of each hour
+	Hourly
+	// Daily rotates at midnight
+	Daily
+)
+
+func (i Interval) truncate(t time.Time) time.Time {
+	switch i {
+	case Hourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case Daily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+func (i Interval) stamp(t time.Time) string {
+	switch i {
+	case Hourly:
+		return t.Format("2006-01-02T15")
+	default:
+		return t.Format("2006-01-02")
+	}
+}
+
 // Writer implements the log.Writer interface.
 // It performs a log-file rotation based on the provided parameters
 type Writer struct {
-	file        io.WriteCloser
-	fileDir     string
-	fileBase    string
-	fileSize    int
-	fileCount   int
-	currSize    int
-	onFileOpErr func(error)
-	msgC        chan string
-	doneC       chan struct{}
-	stopC       chan struct{}
+	file         io.WriteCloser
+	fileDir      string
+	fileBase     string
+	fileSize     int
+	fileSizeSet  bool
+	fileCount    int
+	fileCountSet bool
+	currSize     int
+	interval     Interval
+	period       time.Time
+	compress     bool
+	maxAge       time.Duration
+	signals      []os.Signal
+	now          func() time.Time
+	onFileOpErr  func(error)
+	formatter    console.Formatter
+	msgC         chan string
+	rotateC      chan struct{}
+	sigC         chan os.Signal
+	doneC        chan struct{}
+	stopC        chan struct{}
+	mu           sync.RWMutex
+	closed       bool
 }
 
 // NewWriter creates a new rotate.Writer
 func NewWriter(path string, opts ...Option) (*Writer, error) {
+	return newWriter(path, time.Now, opts...)
+}
+
+func newWriter(path string, now func() time.Time, opts ...Option) (*Writer, error) {
 	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
 	if err != nil {
 		return nil, fmt.Errorf("os.mkdirall %q: %w", filepath.Dir(path), err)
 	}
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return nil, fmt.Errorf("open-file %q: %w", path, err)
-	}
 	w := &Writer{
-		file:        f,
 		fileDir:     filepath.Dir(path),
 		fileBase:    filepath.Base(path),
 		fileSize:    1 * MB,
 		fileCount:   5,
-		currSize:    0,
+		now:         now,
 		onFileOpErr: func(err error) { panic(err) },
+		formatter:   console.TextFormatter{},
 		msgC:        make(chan string, 10000),
+		rotateC:     make(chan struct{}, 1),
 		doneC:       make(chan struct{}),
 		stopC:       make(chan struct{}),
 	}
@@ -94,65 +205,267 @@ func NewWriter(path string, opts ...Option) (*Writer, error) {
 			return nil, err
 		}
 	}
-	go func() {
-		defer close(w.doneC)
-		for {
-			select {
-			case <-w.stopC:
-				return
-			case s := <-w.msgC:
-				n, _ := w.file.Write([]byte(s + "\n"))
-				w.currSize += n
-				if w.currSize >= w.fileSize {
+	if w.interval != IntervalNone {
+		if !w.fileSizeSet {
+			w.fileSize = 0
+		}
+		if !w.fileCountSet {
+			w.fileCount = 0
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open-file %q: %w", path, err)
+	}
+	w.file = f
+	w.period = w.interval.truncate(w.now())
+	if fi, err := f.Stat(); err == nil && fi.Size() > 0 {
+		// continue an existing file
+		w.currSize = int(fi.Size())
+		w.period = w.interval.truncate(fi.ModTime())
+	}
+
+	if len(w.signals) > 0 {
+		w.sigC = make(chan os.Signal, 1)
+		signal.Notify(w.sigC, w.signals...)
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *Writer) run() {
+	defer close(w.doneC)
+	for {
+		select {
+		case <-w.stopC:
+			// flush pending messages and rotations
+			for {
+				select {
+				case s := <-w.msgC:
+					w.write(s)
+				case <-w.rotateC:
 					w.rotate()
+				default:
+					return
 				}
 			}
+		case s := <-w.msgC:
+			w.write(s)
+		case <-w.rotateC:
+			w.rotate()
+		case <-w.sigC:
+			w.rotate()
 		}
-	}()
-	return w, nil
+	}
+}
+
+func (w *Writer) write(s string) {
+	if w.interval != IntervalNone && !w.interval.truncate(w.now()).Equal(w.period) {
+		w.rotate()
+	}
+	n, _ := w.file.Write([]byte(s + "\n"))
+	w.currSize += n
+	if w.fileSize > 0 && w.currSize >= w.fileSize {
+		w.rotate()
+	}
 }
 
-// Close closes the writer and all related resources
+// Close flushes pending entries and closes the writer and all related resources
 func (w *Writer) Close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	if w.sigC != nil {
+		signal.Stop(w.sigC)
+	}
 	close(w.stopC)
 	<-w.doneC
 	w.file.Close()
 }
 
-// Write writes an entry to the current log-file
+// Write writes an entry to the current log-file. Entries written after Close are discarded.
 func (w *Writer) Write(e entry.Entry) {
-	w.msgC <- fmt.Sprintf("%s [%s] [%s] [%s] %s", e.Time.Format("2006-01-02T15:04:05.000"), e.Program, e.Component, e.Level, e.Message)
+	s := w.formatter.Format(e)
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return
+	}
+	w.msgC <- s
+}
+
+// Rotate requests a rotation of the current log-file, independent of its size and age
+func (w *Writer) Rotate() {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.rotateC <- struct{}{}:
+	default:
+		// a rotation is already pending
+	}
+}
+
+func (w *Writer) handleErr(err error) {
+	if err != nil {
+		w.onFileOpErr(err)
+	}
 }
 
 func (w *Writer) rotate() {
-	handleErr := func(err error) {
+	w.file.Close()
+	base := filepath.Join(w.fileDir, w.fileBase)
+
+	// the file may have been moved away by an external tool, in which case it's just reopened
+	if _, err := os.Stat(base); err == nil {
+		var rotated string
+		if w.interval == IntervalNone {
+			w.shiftNumbered()
+			rotated = base + ".001"
+		} else {
+			rotated = w.uniqueName(base + "." + w.interval.stamp(w.period))
+		}
+		w.handleErr(os.Rename(base, rotated))
+		if w.compress {
+			w.handleErr(compressFile(rotated))
+		}
+	}
+	w.removeExpired()
+
+	f, err := os.OpenFile(base, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	w.handleErr(err)
+	w.file = f
+	w.currSize = 0
+	w.period = w.interval.truncate(w.now())
+}
+
+// shiftNumbered renames "base.001" to "base.002" and so on, removing files exceeding the file-count
+func (w *Writer) shiftNumbered() {
+	type numbered struct {
+		path string
+		num  int
+		ext  string
+	}
+	var nfs []numbered
+	for _, path := range w.rotatedFiles() {
+		suffix := strings.TrimPrefix(filepath.Base(path), w.fileBase+".")
+		ext := ""
+		if strings.HasSuffix(suffix, gzExt) {
+			ext = gzExt
+			suffix = strings.TrimSuffix(suffix, gzExt)
+		}
+		num, err := strconv.Atoi(suffix)
 		if err != nil {
-			w.onFileOpErr(err)
+			continue
 		}
+		nfs = append(nfs, numbered{path: path, num: num, ext: ext})
 	}
-	w.file.Close()
-	pattern := filepath.Join(w.fileDir, w.fileBase+".*")
-	matches, _ := filepath.Glob(pattern)
+	sort.Slice(nfs, func(i, j int) bool {
+		return nfs[i].num > nfs[j].num
+	})
+	for _, nf := range nfs {
+		if nf.num >= w.fileCount {
+			w.handleErr(os.Remove(nf.path))
+			continue
+		}
+		new := filepath.Join(w.fileDir, w.fileBase+fmt.Sprintf(".%03d", nf.num+1)+nf.ext)
+		w.handleErr(os.Rename(nf.path, new))
+	}
+}
 
-	//remove oldest
-	for len(matches) > w.fileCount {
-		last := matches[len(matches)-1]
-		err := os.Remove(last)
-		handleErr(err)
-		matches = matches[:len(matches)-1]
+// uniqueName returns name, or name with a counter suffix if name is already taken by a (compressed) file
+func (w *Writer) uniqueName(name string) string {
+	exists := func(p string) bool {
+		_, err := os.Stat(p)
+		if err == nil {
+			return true
+		}
+		_, err = os.Stat(p + gzExt)
+		return err == nil
+	}
+	if !exists(name) {
+		return name
+	}
+	for i := 1; ; i++ {
+		if n := fmt.Sprintf("%s.%d", name, i); !exists(n) {
+			return n
+		}
+	}
+}
+
+func (w *Writer) rotatedFiles() []string {
+	matches, _ := filepath.Glob(filepath.Join(w.fileDir, w.fileBase+".*"))
+	return matches
+}
+
+// removeExpired removes rotated files exceeding the max-age and, for time based rotation, the file-count
+func (w *Writer) removeExpired() {
+	type rotatedFile struct {
+		path    string
+		modTime time.Time
+	}
+	var rfs []rotatedFile
+	for _, path := range w.rotatedFiles() {
+		fi, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		rfs = append(rfs, rotatedFile{path: path, modTime: fi.ModTime()})
 	}
+	sort.Slice(rfs, func(i, j int) bool {
+		return rfs[i].modTime.After(rfs[j].modTime)
+	})
+	for i, rf := range rfs {
+		switch {
+		case w.maxAge > 0 && w.now().Sub(rf.modTime) > w.maxAge:
+			w.handleErr(os.Remove(rf.path))
+		case w.interval != IntervalNone && w.fileCount > 0 && i >= w.fileCount:
+			w.handleErr(os.Remove(rf.path))
+		}
+	}
+}
 
-	//rename others
-	for i := len(matches) - 1; i >= 0; i-- {
-		new := filepath.Join(w.fileDir, w.fileBase+fmt.Sprintf(".%03d", i+2))
-		err := os.Rename(matches[i], new)
-		handleErr(err)
+// compressFile gzips the file at path to "path.gz" and removes the original. The modification time is retained.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open %q: %w", path, err)
+	}
+	defer src.Close()
+	fi, err := src.Stat()
+	if err != nil {
+		return fmt.Errorf("stat %q: %w", path, err)
 	}
-	err := os.Rename(filepath.Join(w.fileDir, w.fileBase), filepath.Join(w.fileDir, w.fileBase+".001"))
-	handleErr(err)
 
-	f, err := os.OpenFile(filepath.Join(w.fileDir, w.fileBase), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-	handleErr(err)
-	w.file = f
-	w.currSize = 0
+	tmp := path + gzExt + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open %q: %w", tmp, err)
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("compress %q: %w", path, err)
+	}
+	if err := os.Rename(tmp, path+gzExt); err != nil {
+		return fmt.Errorf("rename %q: %w", tmp, err)
+	}
+	os.Chtimes(path+gzExt, fi.ModTime(), fi.ModTime())
+	src.Close()
+	return os.Remove(path)
 }
@@ -0,0 +1,169 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/testutil"
+)
+
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func dirFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	des, err := os.ReadDir(dir)
+	testutil.AssertNoErr(t, err, "read-dir %q", dir)
+	var names []string
+	for _, de := range des {
+		names = append(names, de.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func readGZ(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	testutil.AssertNoErr(t, err, "open %q", path)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	testutil.AssertNoErr(t, err, "gzip-reader %q", path)
+	bs, err := io.ReadAll(zr)
+	testutil.AssertNoErr(t, err, "read %q", path)
+	return string(bs)
+}
+
+func touch(t *testing.T, path string, mt time.Time) {
+	t.Helper()
+	testutil.AssertNoErr(t, os.Chtimes(path, mt, mt), "chtimes %q", path)
+}
+
+type msgFormatter struct{}
+
+func (msgFormatter) Format(e entry.Entry) string { return e.Message }
+
+func TestSizeRotationContinuesExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	err := os.WriteFile(path, []byte(strings.Repeat("x", KB-10)+"\n"), 0644)
+	testutil.AssertNoErr(t, err, "write-file")
+
+	w, err := NewWriter(path, WithFileSize(KB), WithFileCount(2), WithCompression(), WithFormatter(msgFormatter{}))
+	testutil.AssertNoErr(t, err, "new-writer")
+	testutil.AssertEqual(t, KB-9, w.currSize)
+
+	// the first entry exceeds the size of the existing file
+	w.Write(entry.Make(entry.LevelInfo, "0123456789"))
+	w.Close()
+	testutil.AssertEqual(t, []string{"app.log", "app.log.001.gz"}, dirFiles(t, dir))
+	testutil.AssertEqual(t, strings.Repeat("x", KB-10)+"\n0123456789\n", readGZ(t, filepath.Join(dir, "app.log.001.gz")))
+
+	// older files are shifted and removed beyond the file count
+	for i := 0; i < 3; i++ {
+		w, err = NewWriter(path, WithFileSize(KB), WithFileCount(2), WithCompression(), WithFormatter(msgFormatter{}))
+		testutil.AssertNoErr(t, err, "new-writer")
+		w.Write(entry.Make(entry.LevelInfo, strings.Repeat("y", KB)))
+		w.Close()
+	}
+	testutil.AssertEqual(t, []string{"app.log", "app.log.001.gz", "app.log.002.gz"}, dirFiles(t, dir))
+}
+
+func TestIntervalRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	clock := &fakeClock{t: time.Date(2023, 4, 5, 23, 59, 0, 0, time.Local)}
+
+	w, err := newWriter(path, clock.now, WithInterval(Daily), WithFormatter(msgFormatter{}))
+	testutil.AssertNoErr(t, err, "new-writer")
+	w.Write(entry.Make(entry.LevelInfo, "day-1"))
+	w.Close()
+
+	// reopen on the next day, the existing file is rotated on the first write
+	touch(t, path, clock.now())
+	clock.add(2 * time.Minute)
+	w, err = newWriter(path, clock.now, WithInterval(Daily), WithFormatter(msgFormatter{}), WithCompression())
+	testutil.AssertNoErr(t, err, "new-writer")
+	w.Write(entry.Make(entry.LevelInfo, "day-2"))
+	w.Close()
+	testutil.AssertEqual(t, []string{"access.log", "access.log.2023-04-05.gz"}, dirFiles(t, dir))
+	testutil.AssertEqual(t, "day-1\n", readGZ(t, filepath.Join(dir, "access.log.2023-04-05.gz")))
+	touch(t, path, clock.now())
+
+	// an explicit rotation within the same period gets a counter suffix
+	w, err = newWriter(path, clock.now, WithInterval(Daily), WithFormatter(msgFormatter{}))
+	testutil.AssertNoErr(t, err, "new-writer")
+	w.Rotate()
+	w.Close()
+	testutil.AssertEqual(t, []string{"access.log", "access.log.2023-04-05.gz", "access.log.2023-04-06"}, dirFiles(t, dir))
+	touch(t, path, clock.now())
+	w, err = newWriter(path, clock.now, WithInterval(Daily), WithFormatter(msgFormatter{}))
+	testutil.AssertNoErr(t, err, "new-writer")
+	w.Write(entry.Make(entry.LevelInfo, "day-2b"))
+	w.Rotate()
+	w.Close()
+	testutil.AssertEqual(t, []string{"access.log", "access.log.2023-04-05.gz", "access.log.2023-04-06", "access.log.2023-04-06.1"}, dirFiles(t, dir))
+}
+
+func TestMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	clock := &fakeClock{t: time.Date(2023, 4, 5, 12, 0, 0, 0, time.Local)}
+	for i, name := range []string{"app.log.2023-03-01.gz", "app.log.2023-04-01.gz"} {
+		p := filepath.Join(dir, name)
+		testutil.AssertNoErr(t, os.WriteFile(p, nil, 0644), "write-file")
+		touch(t, p, time.Date(2023, 3+time.Month(i), 1, 12, 0, 0, 0, time.Local))
+	}
+
+	w, err := newWriter(path, clock.now, WithInterval(Hourly), WithMaxAge(30*24*time.Hour), WithFormatter(msgFormatter{}))
+	testutil.AssertNoErr(t, err, "new-writer")
+	w.Write(entry.Make(entry.LevelInfo, "noon"))
+	clock.add(time.Hour)
+	w.Write(entry.Make(entry.LevelInfo, "afternoon"))
+	w.Close()
+	testutil.AssertEqual(t, []string{"app.log", "app.log.2023-04-01.gz", "app.log.2023-04-05T12"}, dirFiles(t, dir))
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	w, err := NewWriter(filepath.Join(t.TempDir(), "app.log"))
+	testutil.AssertNoErr(t, err, "new-writer")
+	w.Close()
+
+	doneC := make(chan struct{})
+	go func() {
+		defer close(doneC)
+		// more than the buffer could hold
+		for i := 0; i < 20000; i++ {
+			w.Write(entry.Make(entry.LevelInfo, "after close"))
+		}
+		w.Rotate()
+		w.Close()
+	}()
+	select {
+	case <-doneC:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("write after close blocks")
+	}
+}
@@ -0,0 +1,162 @@
+package log
+
+import (
+	"sync"
+	"time"
+
+	"github.com/best4tires/kit/log/entry"
+)
+
+// SamplerOption is the Sampler option type
+type SamplerOption func(s *Sampler)
+
+// WithSampleInterval defines the period, after which the counters are reset and summaries are written. It defaults to one second.
+func WithSampleInterval(d time.Duration) SamplerOption {
+	return func(s *Sampler) {
+		if d > 0 {
+			s.interval = d
+		}
+	}
+}
+
+// WithSampleFirst defines the number of entries per template and level, which pass unsampled in each interval. It defaults to 100.
+func WithSampleFirst(n int) SamplerOption {
+	return func(s *Sampler) {
+		if n >= 0 {
+			s.first = uint64(n)
+		}
+	}
+}
+
+// WithSampleThereafter defines, that after the first entries only every m-th entry passes. With m = 0 all further entries
+// are suppressed. It defaults to 100.
+func WithSampleThereafter(m int) SamplerOption {
+	return func(s *Sampler) {
+		if m >= 0 {
+			s.thereafter = uint64(m)
+		}
+	}
+}
+
+type sampleKey struct {
+	level    entry.Level
+	template string
+}
+
+type sampleCounter struct {
+	count      uint64
+	suppressed uint64
+	last       entry.Entry
+}
+
+// Sampler is a writer middleware for a log-chain, which limits entries by message template and level.
+// In each interval the first n entries pass, thereafter 1 in m. At the end of each interval a summary entry is written
+// for each template with suppressed entries.
+type Sampler struct {
+	next       Writer
+	interval   time.Duration
+	first      uint64
+	thereafter uint64
+	mu         sync.Mutex
+	counters   map[sampleKey]*sampleCounter
+	stopC      chan struct{}
+	doneC      chan struct{}
+	closeOnce  sync.Once
+	closeMu    sync.RWMutex
+	closed     bool
+}
+
+// NewSampler creates a new Sampler
+func NewSampler(next Writer, opts ...SamplerOption) *Sampler {
+	s := &Sampler{
+		next:       next,
+		interval:   time.Second,
+		first:      100,
+		thereafter: 100,
+		counters:   map[sampleKey]*sampleCounter{},
+		stopC:      make(chan struct{}),
+		doneC:      make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	go func() {
+		defer close(s.doneC)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stopC:
+				return
+			case <-ticker.C:
+				s.flush()
+			}
+		}
+	}()
+	return s
+}
+
+// Close writes pending summaries and closes the next writer
+func (s *Sampler) Close() {
+	s.closeOnce.Do(func() {
+		s.closeMu.Lock()
+		s.closed = true
+		s.closeMu.Unlock()
+		close(s.stopC)
+		<-s.doneC
+		s.flush()
+		s.next.Close()
+	})
+}
+
+// Write passes the entry to the next writer, unless it's suppressed. Entries written after Close are dropped.
+func (s *Sampler) Write(e entry.Entry) {
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		return
+	}
+	key := sampleKey{level: e.Level, template: e.Template}
+	if key.template == "" {
+		key.template = e.Message
+	}
+
+	s.mu.Lock()
+	c, ok := s.counters[key]
+	if !ok {
+		c = &sampleCounter{}
+		s.counters[key] = c
+	}
+	c.count++
+	pass := c.count <= s.first || (s.thereafter > 0 && (c.count-s.first)%s.thereafter == 0)
+	if !pass {
+		c.suppressed++
+		c.last = e
+	}
+	s.mu.Unlock()
+
+	if pass {
+		s.next.Write(e)
+	}
+}
+
+// flush writes summaries for all suppressed entries and resets the counters
+func (s *Sampler) flush() {
+	s.mu.Lock()
+	counters := s.counters
+	s.counters = map[sampleKey]*sampleCounter{}
+	s.mu.Unlock()
+
+	for key, c := range counters {
+		if c.suppressed == 0 {
+			continue
+		}
+		e := entry.Make(key.level, "sampler: suppressed %d entries like %q", c.suppressed, c.last.Message)
+		e.Program = c.last.Program
+		e.Component = c.last.Component
+		s.next.Write(e.WithFields(
+			entry.F("suppressed", c.suppressed),
+			entry.F("template", key.template),
+		))
+	}
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestSampler(t *testing.T) {
+	rw := &recordWriter{}
+	s := NewSampler(rw, WithSampleInterval(time.Hour), WithSampleFirst(2), WithSampleThereafter(3))
+
+	for i := 0; i < 10; i++ {
+		s.Write(entry.Make(entry.LevelWarn, "dependency %d flapping", i))
+	}
+	s.Write(entry.Make(entry.LevelError, "dependency %d flapping", 99))
+	s.flush()
+	s.Write(entry.Make(entry.LevelWarn, "dependency %d flapping", 100))
+	s.Close()
+
+	testutil.AssertEqual(t, []string{
+		"dependency 0 flapping",
+		"dependency 1 flapping",
+		"dependency 4 flapping",
+		"dependency 7 flapping",
+		"dependency 99 flapping",
+		`sampler: suppressed 6 entries like "dependency 9 flapping"`,
+		"dependency 100 flapping",
+	}, rw.messages())
+
+	summary := rw.entries[5]
+	testutil.AssertEqual(t, entry.LevelWarn, summary.Level)
+	testutil.AssertEqual(t, entry.MakeFields("suppressed", uint64(6), "template", "dependency %d flapping"), summary.Fields)
+}
+
+func TestSamplerInMultiWriter(t *testing.T) {
+	sampled := &recordWriter{}
+	all := &recordWriter{}
+	mw := NewMultiWriter(all, NewSampler(sampled, WithSampleFirst(1), WithSampleThereafter(0)))
+
+	for i := 0; i < 5; i++ {
+		mw.Write(entry.Make(entry.LevelInfo, "tick %d", i))
+	}
+	mw.Close()
+
+	testutil.AssertEqual(t, 5, len(all.entries))
+	testutil.AssertEqual(t, []string{"tick 0", `sampler: suppressed 4 entries like "tick 4"`}, sampled.messages())
+}
+
+func TestSamplerWriteAfterClose(t *testing.T) {
+	gw := newGateWriter()
+	close(gw.gate)
+	s := NewSampler(gw, WithSampleInterval(time.Hour))
+	s.Write(entry.Make(entry.LevelInfo, "before close"))
+	s.Close()
+	s.Write(entry.Make(entry.LevelInfo, "after close"))
+	s.Close()
+
+	testutil.AssertEqual(t, true, gw.closed)
+	testutil.AssertEqual(t, []string{"before close"}, gw.msgs)
+}
@@ -0,0 +1,294 @@
+// Package syslog provides a log.Writer sending RFC 5424 messages to a syslog daemon
+package syslog
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/best4tires/kit/log/entry"
+)
+
+// Facility defines the syslog facility
+type Facility int
+
+const (
+	FacilityUser   Facility = 1
+	FacilityDaemon Facility = 3
+	FacilityLocal0 Facility = 16
+	FacilityLocal1 Facility = 17
+	FacilityLocal2 Facility = 18
+	FacilityLocal3 Facility = 19
+	FacilityLocal4 Facility = 20
+	FacilityLocal5 Facility = 21
+	FacilityLocal6 Facility = 22
+	FacilityLocal7 Facility = 23
+)
+
+// Severity defines the syslog severity
+type Severity int
+
+const (
+	SeverityEmergency Severity = iota
+	SeverityAlert
+	SeverityCritical
+	SeverityError
+	SeverityWarning
+	SeverityNotice
+	SeverityInformational
+	SeverityDebug
+)
+
+// SeverityOf maps a log level to a syslog severity
+func SeverityOf(l entry.Level) Severity {
+	switch l {
+	case entry.LevelDebug:
+		return SeverityDebug
+	case entry.LevelInfo, entry.LevelAccess:
+		return SeverityInformational
+	case entry.LevelImportant:
+		return SeverityNotice
+	case entry.LevelWarn:
+		return SeverityWarning
+	case entry.LevelError:
+		return SeverityError
+	case entry.LevelFatal:
+		return SeverityCritical
+	default:
+		return SeverityInformational
+	}
+}
+
+// structured data ids for entry fields and the caller. 32473 is the private enterprise number reserved for documentation (RFC 5612).
+const (
+	fieldsSDID = "fields@32473"
+	callerSDID = "caller@32473"
+)
+
+// local syslog sockets probed by NewLocalWriter
+var localSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Option is the syslog-writer option type
+type Option func(w *Writer) error
+
+// WithFacility defines the facility of all messages. It defaults to FacilityUser
+func WithFacility(f Facility) Option {
+	return func(w *Writer) error {
+		if f < 0 || f > FacilityLocal7 {
+			return fmt.Errorf("invalid facility: %d", f)
+		}
+		w.facility = f
+		return nil
+	}
+}
+
+// WithAppName defines the app-name of messages, whose entry has no program
+func WithAppName(name string) Option {
+	return func(w *Writer) error {
+		w.appName = name
+		return nil
+	}
+}
+
+// WithHostname overrides the hostname, which defaults to os.Hostname
+func WithHostname(name string) Option {
+	return func(w *Writer) error {
+		w.hostname = name
+		return nil
+	}
+}
+
+// WithErrHandler defines a handler for write errors. Errors are printed to stderr by default.
+func WithErrHandler(h func(err error)) Option {
+	return func(w *Writer) error {
+		w.onErr = h
+		return nil
+	}
+}
+
+// Writer implements the log.Writer interface.
+// It sends RFC 5424 messages over unix datagram, udp, tcp or unix stream sockets.
+type Writer struct {
+	network  string
+	addr     string
+	facility Facility
+	appName  string
+	hostname string
+	pid      int
+	onErr    func(error)
+	mu       sync.Mutex
+	conn     net.Conn
+	closed   bool
+}
+
+// NewWriter creates a new syslog.Writer sending to addr. Network is one of "unixgram", "unix", "udp" or "tcp".
+func NewWriter(network, addr string, opts ...Option) (*Writer, error) {
+	switch network {
+	case "unixgram", "unix", "udp", "tcp":
+	default:
+		return nil, fmt.Errorf("invalid network %q", network)
+	}
+	hostname, _ := os.Hostname()
+	w := &Writer{
+		network:  network,
+		addr:     addr,
+		facility: FacilityUser,
+		appName:  filepath.Base(os.Args[0]),
+		hostname: hostname,
+		pid:      os.Getpid(),
+		onErr: func(err error) {
+			fmt.Fprintf(os.Stderr, "syslog-writer: %v\n", err)
+		},
+	}
+	for _, opt := range opts {
+		if err := opt(w); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// NewLocalWriter creates a new syslog.Writer sending to the local syslog socket
+func NewLocalWriter(opts ...Option) (*Writer, error) {
+	for _, path := range localSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			w, err := NewWriter(network, path, opts...)
+			if err == nil {
+				return w, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no local syslog socket found in %v", localSockets)
+}
+
+func (w *Writer) connect() error {
+	conn, err := net.Dial(w.network, w.addr)
+	if err != nil {
+		return fmt.Errorf("dial %s %q: %w", w.network, w.addr, err)
+	}
+	w.conn = conn
+	return nil
+}
+
+// Close closes the connection
+func (w *Writer) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+}
+
+// Write sends an entry. On failure the connection is re-established once. Entries written after Close are discarded.
+func (w *Writer) Write(e entry.Entry) {
+	msg := w.format(e)
+	if w.network == "tcp" || w.network == "unix" {
+		// octet counting framing (RFC 6587)
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	if w.conn == nil {
+		if err := w.connect(); err != nil {
+			w.onErr(err)
+			return
+		}
+	}
+	if _, err := w.conn.Write([]byte(msg)); err != nil {
+		w.conn.Close()
+		if err := w.connect(); err != nil {
+			w.conn = nil
+			w.onErr(err)
+			return
+		}
+		if _, err := w.conn.Write([]byte(msg)); err != nil {
+			w.onErr(fmt.Errorf("write: %w", err))
+		}
+	}
+}
+
+func (w *Writer) format(e entry.Entry) string {
+	appName := e.Program
+	if appName == "" {
+		appName = w.appName
+	}
+	pri := int(w.facility)*8 + int(SeverityOf(e.Level))
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		pri,
+		e.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		header(w.hostname, 255),
+		header(appName, 48),
+		w.pid,
+		header(e.Component, 32),
+		structuredData(e),
+		e.Message,
+	)
+}
+
+// header returns s restricted to printable US-ASCII and the maximum length, or the nil value "-"
+func header(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func structuredData(e entry.Entry) string {
+	if len(e.Fields) == 0 && e.Caller == nil {
+		return "-"
+	}
+	sb := strings.Builder{}
+	if len(e.Fields) > 0 {
+		sb.WriteString("[" + fieldsSDID)
+		for _, f := range e.Fields {
+			writeParam(&sb, f.Key, f.Value)
+		}
+		sb.WriteString("]")
+	}
+	if e.Caller != nil {
+		sb.WriteString("[" + callerSDID)
+		writeParam(&sb, "file", e.Caller.File)
+		writeParam(&sb, "line", e.Caller.Line)
+		writeParam(&sb, "function", e.Caller.Function)
+		sb.WriteString("]")
+	}
+	return sb.String()
+}
+
+func writeParam(sb *strings.Builder, key string, value any) {
+	name := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	if name == "" {
+		return
+	}
+	v := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(fmt.Sprintf("%v", value))
+	sb.WriteString(fmt.Sprintf(" %s=\"%s\"", name, v))
+}
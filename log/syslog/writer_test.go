@@ -0,0 +1,107 @@
+package syslog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/testutil"
+)
+
+func testEntry() entry.Entry {
+	return entry.Entry{
+		Time:      time.Date(2023, 4, 5, 6, 7, 8, 9000, time.UTC),
+		Level:     entry.LevelWarn,
+		Program:   "prog",
+		Component: "comp",
+		Message:   "disk almost full",
+		Fields:    entry.MakeFields("path", "/var", "quote", `a "b" ]`),
+	}
+}
+
+func TestWriterUnixgram(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	testutil.AssertNoErr(t, err, "listen")
+	defer conn.Close()
+
+	w, err := NewWriter("unixgram", addr, WithFacility(FacilityLocal0), WithHostname("host"))
+	testutil.AssertNoErr(t, err, "new-writer")
+	defer w.Close()
+	w.Write(testEntry())
+
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	testutil.AssertNoErr(t, err, "read")
+
+	want := `<132>1 2023-04-05T06:07:08.000009Z host prog ` + strconv.Itoa(w.pid) +
+		` comp [fields@32473 path="/var" quote="a \"b\" \]"] disk almost full`
+	testutil.AssertEqual(t, want, string(buf[:n]))
+}
+
+func TestWriterTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	testutil.AssertNoErr(t, err, "listen")
+	defer l.Close()
+	recvC := make(chan string, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			ls, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(ls))
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				return
+			}
+			recvC <- string(msg)
+		}
+	}()
+
+	w, err := NewWriter("tcp", l.Addr().String(), WithHostname("host"), WithAppName("app"))
+	testutil.AssertNoErr(t, err, "new-writer")
+	defer w.Close()
+
+	levels := []entry.Level{entry.LevelAccess, entry.LevelFatal}
+	for _, l := range levels {
+		w.Write(entry.Entry{Time: time.Unix(0, 0).UTC(), Level: l, Message: "msg"})
+	}
+	for _, want := range []string{"<14>1 ", "<10>1 "} {
+		select {
+		case msg := <-recvC:
+			testutil.AssertEqual(t, true, strings.HasPrefix(msg, want))
+			testutil.AssertEqual(t, true, strings.HasSuffix(msg, " host app "+strconv.Itoa(w.pid)+" - - msg"))
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no message received")
+		}
+	}
+}
+
+func TestSeverityOf(t *testing.T) {
+	want := map[entry.Level]Severity{
+		entry.LevelDebug:     SeverityDebug,
+		entry.LevelInfo:      SeverityInformational,
+		entry.LevelAccess:    SeverityInformational,
+		entry.LevelImportant: SeverityNotice,
+		entry.LevelWarn:      SeverityWarning,
+		entry.LevelError:     SeverityError,
+		entry.LevelFatal:     SeverityCritical,
+	}
+	for _, l := range entry.Levels() {
+		testutil.AssertEqual(t, want[l], SeverityOf(l))
+	}
+}
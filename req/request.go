@@ -2,10 +2,14 @@ package req
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 
+	"github.com/best4tires/kit/errs"
 	"github.com/best4tires/kit/srv"
 )
 
@@ -15,8 +19,13 @@ type Header struct {
 }
 
 func GetJSON[T any](clt *http.Client, url string, headers ...Header) (T, error) {
+	return GetJSONCtx[T](context.Background(), clt, url, headers...)
+}
+
+// GetJSONCtx performs a GET request bound to ctx. A request id and trace parent carried by ctx (see srv.RequestID and srv.TraceContext) are forwarded.
+func GetJSONCtx[T any](ctx context.Context, clt *http.Client, url string, headers ...Header) (T, error) {
 	var t T
-	r, err := http.NewRequest(http.MethodGet, url, nil)
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return t, fmt.Errorf("new-request %q: %w", url, err)
 	}
@@ -25,13 +34,18 @@ func GetJSON[T any](clt *http.Client, url string, headers ...Header) (T, error)
 }
 
 func PostJSON[T any](clt *http.Client, url string, data any, headers ...Header) (T, error) {
+	return PostJSONCtx[T](context.Background(), clt, url, data, headers...)
+}
+
+// PostJSONCtx performs a POST request bound to ctx. A request id and trace parent carried by ctx (see srv.RequestID and srv.TraceContext) are forwarded.
+func PostJSONCtx[T any](ctx context.Context, clt *http.Client, url string, data any, headers ...Header) (T, error) {
 	var t T
 	buf := &bytes.Buffer{}
 	err := json.NewEncoder(buf).Encode(data)
 	if err != nil {
 		return t, fmt.Errorf("json.encode: %w", err)
 	}
-	r, err := http.NewRequest(http.MethodPost, url, buf)
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, url, buf)
 	if err != nil {
 		return t, fmt.Errorf("new-request %q: %w", url, err)
 	}
@@ -41,6 +55,12 @@ func PostJSON[T any](clt *http.Client, url string, data any, headers ...Header)
 }
 
 func doJSON[T any](clt *http.Client, r *http.Request, headers ...Header) (T, error) {
+	if id := srv.RequestIDFromContext(r.Context()); id != "" {
+		r.Header.Set(srv.HeaderRequestID, id)
+	}
+	if tp, ok := srv.TraceParentFromContext(r.Context()); ok {
+		r.Header.Set(srv.HeaderTraceParent, tp.String())
+	}
 	for _, h := range headers {
 		r.Header.Add(h.Key, h.Value)
 	}
@@ -52,7 +72,7 @@ func doJSON[T any](clt *http.Client, r *http.Request, headers ...Header) (T, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return t, fmt.Errorf("status-code: %s", resp.Status)
+		return t, fmt.Errorf("status-code: %w", responseError(resp))
 	}
 	err = json.NewDecoder(resp.Body).Decode(&t)
 	if err != nil {
@@ -60,3 +80,26 @@ func doJSON[T any](clt *http.Client, r *http.Request, headers ...Header) (T, err
 	}
 	return t, nil
 }
+
+// maxErrorBodySize limits the error response body read by responseError
+const maxErrorBodySize = 64 << 10
+
+// responseError maps a non-2xx response to an error matching the errs error of the status code.
+// Problem details in the body are returned as *srv.Problem, which unwraps to the errs error including field errors.
+func responseError(resp *http.Response) error {
+	ct := resp.Header.Get(srv.HeaderContentType)
+	if strings.HasPrefix(ct, srv.ContentTypeProblemJSON) || strings.HasPrefix(ct, "application/json") {
+		var p srv.Problem
+		if err := json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&p); err == nil {
+			if p.Status == 0 {
+				p.Status = resp.StatusCode
+			}
+			return &p
+		}
+	}
+	err := errs.FromHTTPStatus(resp.StatusCode)
+	if err == nil {
+		err = errs.Internal()
+	}
+	return fmt.Errorf("%s: %w", resp.Status, err)
+}
@@ -0,0 +1,60 @@
+package req
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/best4tires/kit/errs"
+	"github.com/best4tires/kit/srv"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestForwardContext(t *testing.T) {
+	var have, haveTP string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		have = r.Header.Get(srv.HeaderRequestID)
+		haveTP = r.Header.Get(srv.HeaderTraceParent)
+		srv.WriteJSON(w, http.StatusOK, map[string]string{"ok": "yes"})
+	}))
+	defer s.Close()
+
+	tp := srv.TraceParent{Version: "00", TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Flags: "01"}
+	ctx := srv.ContextWithTraceParent(srv.ContextWithRequestID(context.Background(), "req-42"), tp)
+	_, err := GetJSONCtx[map[string]string](ctx, s.Client(), s.URL)
+	testutil.AssertNoErr(t, err, "get-json")
+	testutil.AssertEqual(t, "req-42", have)
+	testutil.AssertEqual(t, tp.String(), haveTP)
+
+	_, err = PostJSONCtx[map[string]string](ctx, s.Client(), s.URL, "data")
+	testutil.AssertNoErr(t, err, "post-json")
+	testutil.AssertEqual(t, "req-42", have)
+
+	_, err = GetJSON[map[string]string](s.Client(), s.URL)
+	testutil.AssertNoErr(t, err, "get-json")
+	testutil.AssertEqual(t, "", have)
+	testutil.AssertEqual(t, "", haveTP)
+}
+
+func TestErrorResponse(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/problem":
+			srv.WriteError(w, r, errs.New(errs.CodeBadArgs, "invalid foo").WithField("name", "required"))
+		default:
+			http.Error(w, "gone", http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	_, err := GetJSON[map[string]string](s.Client(), s.URL+"/problem")
+	testutil.AssertEqual(t, true, errors.Is(err, errs.BadArgs()))
+	testutil.AssertEqual(t, []errs.FieldError{{Field: "name", Message: "required"}}, errs.FieldsOf(err))
+	testutil.AssertEqual(t, "status-code: 400 Bad Request: bad arguments: invalid foo: name: required", err.Error())
+
+	_, err = GetJSON[map[string]string](s.Client(), s.URL+"/plain")
+	testutil.AssertEqual(t, true, errors.Is(err, errs.NotFound()))
+	testutil.AssertEqual(t, "status-code: 404 Not Found: not found", err.Error())
+}
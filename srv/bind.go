@@ -0,0 +1,237 @@
+package srv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/best4tires/kit/convert"
+	"github.com/best4tires/kit/errs"
+	"github.com/gorilla/mux"
+)
+
+// DefaultMaxBodySize limits request bodies read by Bind
+const DefaultMaxBodySize = 1 << 20
+
+type BindOption func(b *binder)
+
+// WithMaxBodySize limits the size of the request body. Larger bodies are rejected with 413.
+func WithMaxBodySize(n int64) BindOption {
+	return func(b *binder) {
+		b.maxBodySize = n
+	}
+}
+
+// WithDisallowUnknownFields rejects json members and form keys not matching a field
+func WithDisallowUnknownFields() BindOption {
+	return func(b *binder) {
+		b.disallowUnknown = true
+	}
+}
+
+type binder struct {
+	maxBodySize     int64
+	disallowUnknown bool
+}
+
+// Bind decodes the request into v, which must be a non-nil pointer. The body is decoded as JSON or,
+// for form content types, into struct fields tagged with `form:"name"`. Struct fields tagged with `query:"name"`
+// and `path:"name"` are set from the query and the path variables. Afterwards v is checked by Validate and,
+// if implemented, by Validator.Validate. Invalid input is reported as errs.BadArgs.
+func Bind(r *http.Request, v any, opts ...BindOption) error {
+	b := &binder{
+		maxBodySize: DefaultMaxBodySize,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("bind: need a non-nil pointer, got %T", v)
+	}
+	if err := b.bindBody(r, v); err != nil {
+		return err
+	}
+	if sv, ok := structValue(rv); ok {
+		if err := bindValues(sv, "query", r.URL.Query(), false); err != nil {
+			return err
+		}
+		if err := bindValues(sv, "path", pathValues(r), false); err != nil {
+			return err
+		}
+	}
+	if err := Validate(v); err != nil {
+		return err
+	}
+	if val, ok := v.(Validator); ok {
+		return validationErr(val.Validate())
+	}
+	if val, ok := rv.Elem().Interface().(Validator); ok {
+		return validationErr(val.Validate())
+	}
+	return nil
+}
+
+// validationErr marks errors of Validator as bad arguments, unless they are already classified
+func validationErr(err error) error {
+	if err == nil || ErrorStatus(err) != http.StatusInternalServerError {
+		return err
+	}
+	return errs.New(errs.CodeBadArgs, "").WithCause(err)
+}
+
+// structValue allocates nil pointers and returns the struct v points to, if any
+func structValue(rv reflect.Value) (reflect.Value, bool) {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	return rv, rv.Kind() == reflect.Struct
+}
+
+func (b *binder) tooLarge() error {
+	return NewProblem(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", b.maxBodySize))
+}
+
+func (b *binder) bindBody(r *http.Request, v any) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	if r.ContentLength > b.maxBodySize {
+		return b.tooLarge()
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get(HeaderContentType))
+	switch mediaType {
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		r.Body = http.MaxBytesReader(nil, r.Body, b.maxBodySize)
+		var err error
+		if mediaType == "multipart/form-data" {
+			err = r.ParseMultipartForm(b.maxBodySize)
+		} else {
+			err = r.ParseForm()
+		}
+		if err != nil {
+			var mbe *http.MaxBytesError
+			if errors.As(err, &mbe) {
+				return b.tooLarge()
+			}
+			return errs.BadArgsf("parse form: %v", err)
+		}
+		sv, ok := structValue(reflect.ValueOf(v))
+		if !ok {
+			return fmt.Errorf("bind form: need a pointer to a struct, got %T", v)
+		}
+		return bindValues(sv, "form", r.PostForm, b.disallowUnknown)
+	default:
+		lr := &io.LimitedReader{R: r.Body, N: b.maxBodySize + 1}
+		dec := json.NewDecoder(lr)
+		if b.disallowUnknown {
+			dec.DisallowUnknownFields()
+		}
+		err := dec.Decode(v)
+		if lr.N <= 0 {
+			return b.tooLarge()
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return errs.BadArgsf("decode json: %v", err)
+		}
+		return nil
+	}
+}
+
+func pathValues(r *http.Request) url.Values {
+	vals := url.Values{}
+	for k, v := range mux.Vars(r) {
+		vals.Set(k, v)
+	}
+	return vals
+}
+
+// bindValues sets the fields of sv tagged with tag from vals
+func bindValues(sv reflect.Value, tag string, vals url.Values, disallowUnknown bool) error {
+	known := map[string]bool{}
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		name, ok := sf.Tag.Lookup(tag)
+		if !ok || name == "-" || !sf.IsExported() {
+			continue
+		}
+		known[name] = true
+		vs, ok := vals[name]
+		if !ok || len(vs) == 0 {
+			continue
+		}
+		if err := setValues(sv.Field(i), vs); err != nil {
+			return errs.New(errs.CodeBadArgs, "").WithField(name, err.Error())
+		}
+	}
+	if disallowUnknown {
+		for k := range vals {
+			if !known[k] {
+				return errs.New(errs.CodeBadArgs, "").WithField(k, "unknown field")
+			}
+		}
+	}
+	return nil
+}
+
+// setValues sets fv from the values; slices take all values, other types the first one
+func setValues(fv reflect.Value, vs []string) error {
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		sl := reflect.MakeSlice(fv.Type(), len(vs), len(vs))
+		for i, s := range vs {
+			if err := setValue(sl.Index(i), s); err != nil {
+				return err
+			}
+		}
+		fv.Set(sl)
+		return nil
+	}
+	return setValue(fv, vs[0])
+}
+
+// setValue coerces s into fv using convert
+func setValue(fv reflect.Value, s string) error {
+	switch fv.Kind() {
+	case reflect.Pointer:
+		pv := reflect.New(fv.Type().Elem())
+		if err := setValue(pv.Elem(), s); err != nil {
+			return err
+		}
+		fv.Set(pv)
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := convert.ToInt(s)
+		if !ok || fv.OverflowInt(int64(n)) {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		fv.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := convert.ToInt(s)
+		if !ok || n < 0 || fv.OverflowUint(uint64(n)) {
+			return fmt.Errorf("invalid unsigned integer %q", s)
+		}
+		fv.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		f, ok := convert.ToFloat(s)
+		if !ok {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		fv.SetFloat(f)
+	case reflect.Bool:
+		fv.SetBool(convert.ToBool(strings.TrimSpace(s)))
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package srv
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/best4tires/kit/errs"
+	"github.com/best4tires/kit/testutil"
+	"github.com/gorilla/mux"
+)
+
+type address struct {
+	City string `json:"city" form:"city" validate:"required"`
+	Zip  string `json:"zip" form:"zip" validate:"len=5,regex=^[0-9]+$"`
+}
+
+type createUser struct {
+	Tenant  string   `json:"-" path:"tenant"`
+	DryRun  bool     `json:"-" query:"dry_run"`
+	Tags    []string `json:"-" query:"tag"`
+	Name    string   `json:"name" form:"name" validate:"required,min=2,max=10"`
+	Email   string   `json:"email" form:"email" validate:"omitempty,email"`
+	Age     int      `json:"age" form:"age" validate:"min=18,max=130"`
+	Role    string   `json:"role" form:"role" validate:"oneof=admin user"`
+	Address *address `json:"address"`
+}
+
+func bindRequest(method, target, contentType, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set(HeaderContentType, contentType)
+	}
+	return mux.SetURLVars(r, map[string]string{"tenant": "acme"})
+}
+
+func TestBind(t *testing.T) {
+	r := bindRequest(http.MethodPost, "/users?dry_run=true&tag=a&tag=b", "application/json",
+		`{"name":"Ann","email":"ann@example.com","age":30,"role":"admin","address":{"city":"Bonn","zip":"53111"}}`)
+	var have createUser
+	testutil.AssertNoErr(t, Bind(r, &have), "bind")
+	testutil.AssertEqual(t, createUser{
+		Tenant:  "acme",
+		DryRun:  true,
+		Tags:    []string{"a", "b"},
+		Name:    "Ann",
+		Email:   "ann@example.com",
+		Age:     30,
+		Role:    "admin",
+		Address: &address{City: "Bonn", Zip: "53111"},
+	}, have)
+}
+
+func TestBindForm(t *testing.T) {
+	form := url.Values{"name": {"Bob"}, "age": {"42"}, "role": {"user"}}
+	r := bindRequest(http.MethodPost, "/users", "application/x-www-form-urlencoded", form.Encode())
+	var have createUser
+	testutil.AssertNoErr(t, Bind(r, &have), "bind")
+	testutil.AssertEqual(t, "Bob", have.Name)
+	testutil.AssertEqual(t, 42, have.Age)
+
+	form.Set("age", "old")
+	r = bindRequest(http.MethodPost, "/users", "application/x-www-form-urlencoded", form.Encode())
+	err := Bind(r, &have)
+	testutil.AssertEqual(t, []errs.FieldError{{Field: "age", Message: `invalid integer "old"`}}, errs.FieldsOf(err))
+
+	form.Set("age", "42")
+	form.Set("nick", "bobby")
+	r = bindRequest(http.MethodPost, "/users", "application/x-www-form-urlencoded", form.Encode())
+	err = Bind(r, &have, WithDisallowUnknownFields())
+	testutil.AssertEqual(t, []errs.FieldError{{Field: "nick", Message: "unknown field"}}, errs.FieldsOf(err))
+}
+
+func TestBindValidation(t *testing.T) {
+	r := bindRequest(http.MethodPost, "/users", "application/json",
+		`{"name":"A","email":"not-an-email","age":12,"role":"root","address":{"zip":"5311x"}}`)
+	var have createUser
+	err := Bind(r, &have)
+	testutil.AssertEqual(t, true, errors.Is(err, errs.BadArgs()))
+	testutil.AssertEqual(t, []errs.FieldError{
+		{Field: "name", Message: "length must be at least 2"},
+		{Field: "email", Message: "must be a valid email address"},
+		{Field: "age", Message: "value must be at least 18"},
+		{Field: "role", Message: "must be one of [admin user]"},
+		{Field: "address.city", Message: "required"},
+		{Field: "address.zip", Message: "must match ^[0-9]+$"},
+	}, errs.FieldsOf(err))
+}
+
+func TestBindLimits(t *testing.T) {
+	r := bindRequest(http.MethodPost, "/users", "application/json", `{"name":"Ann","nick":"annie"}`)
+	err := Bind(r, &createUser{}, WithDisallowUnknownFields())
+	testutil.AssertEqual(t, http.StatusBadRequest, ErrorStatus(err))
+
+	r = bindRequest(http.MethodPost, "/users", "application/json", `{"name":"`+strings.Repeat("x", 100)+`"}`)
+	r.ContentLength = -1
+	err = Bind(r, &createUser{}, WithMaxBodySize(64))
+	testutil.AssertEqual(t, http.StatusRequestEntityTooLarge, ErrorStatus(err))
+
+	r = bindRequest(http.MethodPost, "/users", "application/json", `{"name":"`+strings.Repeat("x", 100)+`"}`)
+	err = Bind(r, &createUser{}, WithMaxBodySize(64))
+	testutil.AssertEqual(t, http.StatusRequestEntityTooLarge, ErrorStatus(err))
+}
+
+func TestValidateZeroValues(t *testing.T) {
+	type order struct {
+		Qty   int     `json:"qty" validate:"min=1"`
+		Zip   string  `json:"zip" validate:"len=5"`
+		Kind  string  `json:"kind" validate:"oneof=a b"`
+		Note  string  `json:"note" validate:"omitempty,min=3"`
+		Limit *int    `json:"limit" validate:"min=1"`
+		Price float64 `json:"price" validate:"omitempty,min=0.5"`
+	}
+	err := Validate(order{})
+	testutil.AssertEqual(t, []errs.FieldError{
+		{Field: "qty", Message: "value must be at least 1"},
+		{Field: "zip", Message: "length must be 5"},
+		{Field: "kind", Message: "must be one of [a b]"},
+	}, errs.FieldsOf(err))
+
+	zero := 0
+	err = Validate(order{Qty: 1, Zip: "53111", Kind: "a", Note: "ab", Limit: &zero, Price: 0.1})
+	testutil.AssertEqual(t, []errs.FieldError{
+		{Field: "note", Message: "length must be at least 3"},
+		{Field: "limit", Message: "value must be at least 1"},
+		{Field: "price", Message: "value must be at least 0.5"},
+	}, errs.FieldsOf(err))
+}
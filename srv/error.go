@@ -0,0 +1,17 @@
+package srv
+
+import (
+	"errors"
+
+	"github.com/best4tires/kit/errs"
+)
+
+// ErrorStatus maps err to a http status code, e.g. errs.NotFound => 404. Problems map to their status,
+// other errors are mapped by errs.HTTPStatus.
+func ErrorStatus(err error) int {
+	var p *Problem
+	if errors.As(err, &p) && p.Status != 0 {
+		return p.Status
+	}
+	return errs.HTTPStatus(err)
+}
@@ -4,6 +4,8 @@ const (
 	HeaderContentType     = "Content-Type"
 	HeaderAccept          = "Accept"
 	HeaderContentEncoding = "Content-Encoding"
+	HeaderRequestID       = "X-Request-ID"
+	HeaderTraceParent     = "traceparent"
 )
 
 const (
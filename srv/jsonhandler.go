@@ -0,0 +1,25 @@
+package srv
+
+import (
+	"context"
+	"net/http"
+)
+
+// JSONHandler adapts a typed function to a http handler. The input is bound from the request by Bind,
+// i.e. decoded from the JSON body, set from query and path variables and validated.
+// The output is written as JSON with status 200, errors are written as problems by WriteError.
+func JSONHandler[In, Out any](fnc func(ctx context.Context, in In) (Out, error), opts ...BindOption) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var in In
+		if err := Bind(r, &in, opts...); err != nil {
+			WriteError(w, r, err)
+			return
+		}
+		out, err := fnc(r.Context(), in)
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, out)
+	}
+}
@@ -0,0 +1,82 @@
+package srv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/best4tires/kit/errs"
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/log/logtest"
+	"github.com/best4tires/kit/testutil"
+)
+
+type updateFoo struct {
+	ID   int    `json:"-" path:"id"`
+	Name string `json:"name"`
+}
+
+func (u updateFoo) Validate() error {
+	if u.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	return nil
+}
+
+type foo struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestJSONHandler(t *testing.T) {
+	rec := logtest.Install(t)
+
+	router := NewRouter()
+	router.PUT("/foos/{id}", JSONHandler(func(ctx context.Context, in updateFoo) (foo, error) {
+		switch in.ID {
+		case 404:
+			return foo{}, fmt.Errorf("foo %d: %w", in.ID, errs.NotFound())
+		case 403:
+			return foo{}, errs.Forbidden()
+		case 500:
+			return foo{}, errors.New("db is down")
+		}
+		return foo{ID: in.ID, Name: in.Name}, nil
+	}))
+	h := router.Handler(RequestID())
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{name: "ok", path: "/foos/1", body: `{"name":"a"}`, status: http.StatusOK, want: `{"id":1,"name":"a"}`},
+		{name: "invalid-json", path: "/foos/1", body: `{"name":`, status: http.StatusBadRequest},
+		{name: "invalid-path", path: "/foos/x", body: `{"name":"a"}`, status: http.StatusBadRequest},
+		{name: "validation", path: "/foos/1", body: `{}`, status: http.StatusBadRequest, want: `{"detail":"bad arguments: name is required","instance":"/foos/1","request_id":"req-1","status":400,"title":"Bad Request","type":"about:blank"}`},
+		{name: "not-found", path: "/foos/404", body: `{"name":"a"}`, status: http.StatusNotFound, want: `{"detail":"foo 404: not found","instance":"/foos/404","request_id":"req-1","status":404,"title":"Not Found","type":"about:blank"}`},
+		{name: "forbidden", path: "/foos/403", body: `{"name":"a"}`, status: http.StatusForbidden},
+		{name: "internal", path: "/foos/500", body: `{"name":"a"}`, status: http.StatusInternalServerError, want: `{"instance":"/foos/500","request_id":"req-1","status":500,"title":"Internal Server Error","type":"about:blank"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, test.path, strings.NewReader(test.body))
+			r.Header.Set(HeaderRequestID, "req-1")
+			resp := httptest.NewRecorder()
+			h.ServeHTTP(resp, r)
+			testutil.AssertEqual(t, test.status, resp.Code)
+			testutil.AssertEqual(t, true, json.Valid(resp.Body.Bytes()))
+			if test.want != "" {
+				testutil.AssertEqual(t, test.want, strings.TrimSpace(resp.Body.String()))
+			}
+		})
+	}
+	rec.AssertLogged(entry.LevelError, "db is down")
+}
@@ -0,0 +1,233 @@
+package srv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds (in seconds) of the request duration histogram
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// routeUnmatched is used as route label for requests, which didn't match any route
+const routeUnmatched = "unmatched"
+
+type MetricsOption func(m *RequestMetrics)
+
+// WithBuckets sets the upper bounds (in seconds) of the request duration histogram
+func WithBuckets(buckets ...float64) MetricsOption {
+	return func(m *RequestMetrics) {
+		bs := append([]float64{}, buckets...)
+		sort.Float64s(bs)
+		m.buckets = bs
+	}
+}
+
+// WithNamespace prefixes all metric names with the namespace, e.g. "orders" => "orders_http_requests_total"
+func WithNamespace(ns string) MetricsOption {
+	return func(m *RequestMetrics) {
+		m.namespace = ns
+	}
+}
+
+type requestKey struct {
+	method string
+	route  string
+	status string
+}
+
+type inFlightKey struct {
+	method string
+	route  string
+}
+
+type requestSeries struct {
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+// RequestMetrics collects request counts, durations and in-flight requests,
+// labelled by method, route template and status class.
+type RequestMetrics struct {
+	namespace string
+	buckets   []float64
+
+	mu       sync.Mutex
+	requests map[requestKey]*requestSeries
+	inFlight map[inFlightKey]int64
+}
+
+// NewRequestMetrics creates new request metrics
+func NewRequestMetrics(opts ...MetricsOption) *RequestMetrics {
+	m := &RequestMetrics{
+		buckets:  DefaultBuckets,
+		requests: map[requestKey]*requestSeries{},
+		inFlight: map[inFlightKey]int64{},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+var defaultMetrics = NewRequestMetrics()
+
+// Metrics records metrics of each request into the default request metrics, which are exposed by MetricsHandler
+func Metrics() func(http.Handler) http.Handler {
+	return defaultMetrics.Middleware()
+}
+
+// MetricsHandler serves the default request metrics in the prometheus text format
+func MetricsHandler() http.Handler {
+	return defaultMetrics.Handler()
+}
+
+// Middleware records metrics of each request
+func (m *RequestMetrics) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := RouteTemplate(r)
+			if route == "" {
+				route = routeUnmatched
+			}
+			ifk := inFlightKey{method: r.Method, route: route}
+			m.addInFlight(ifk, 1)
+			defer m.addInFlight(ifk, -1)
+
+			sw := NewStatusWriter(w)
+			t0 := time.Now()
+			next.ServeHTTP(sw, r)
+			m.observe(requestKey{method: r.Method, route: route, status: statusClass(sw.Code())}, time.Since(t0))
+		})
+	}
+}
+
+func statusClass(code int) string {
+	if code == 0 {
+		// the header hasn't been written explicitly, so net/http responds with 200
+		code = http.StatusOK
+	}
+	return fmt.Sprintf("%dxx", code/100)
+}
+
+func (m *RequestMetrics) addInFlight(k inFlightKey, d int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight[k] += d
+}
+
+func (m *RequestMetrics) observe(k requestKey, d time.Duration) {
+	secs := d.Seconds()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.requests[k]
+	if !ok {
+		s = &requestSeries{buckets: make([]uint64, len(m.buckets))}
+		m.requests[k] = s
+	}
+	s.count++
+	s.sum += secs
+	for i, b := range m.buckets {
+		if secs <= b {
+			s.buckets[i]++
+		}
+	}
+}
+
+// Handler serves the metrics in the prometheus text format
+func (m *RequestMetrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		bw := bufio.NewWriter(w)
+		m.WriteText(bw)
+		bw.Flush()
+	})
+}
+
+// WriteText writes the metrics in the prometheus text format to w
+func (m *RequestMetrics) WriteText(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reqKeys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		reqKeys = append(reqKeys, k)
+	}
+	sort.Slice(reqKeys, func(i, j int) bool {
+		a, b := reqKeys[i], reqKeys[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.status < b.status
+	})
+	ifKeys := make([]inFlightKey, 0, len(m.inFlight))
+	for k := range m.inFlight {
+		ifKeys = append(ifKeys, k)
+	}
+	sort.Slice(ifKeys, func(i, j int) bool {
+		a, b := ifKeys[i], ifKeys[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		return a.method < b.method
+	})
+
+	name := m.name("http_requests_total")
+	fmt.Fprintf(w, "# HELP %s Total number of HTTP requests.\n", name)
+	fmt.Fprintf(w, "# TYPE %s counter\n", name)
+	for _, k := range reqKeys {
+		fmt.Fprintf(w, "%s{%s} %d\n", name, k.labels(), m.requests[k].count)
+	}
+
+	name = m.name("http_request_duration_seconds")
+	fmt.Fprintf(w, "# HELP %s Duration of HTTP requests in seconds.\n", name)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", name)
+	for _, k := range reqKeys {
+		s := m.requests[k]
+		lbls := k.labels()
+		for i, b := range m.buckets {
+			fmt.Fprintf(w, "%s_bucket{%s,le=%q} %d\n", name, lbls, formatFloat(b), s.buckets[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, lbls, s.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", name, lbls, formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, lbls, s.count)
+	}
+
+	name = m.name("http_requests_in_flight")
+	fmt.Fprintf(w, "# HELP %s Number of HTTP requests currently being served.\n", name)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+	for _, k := range ifKeys {
+		fmt.Fprintf(w, "%s{method=\"%s\",route=\"%s\"} %d\n", name, escapeLabel(k.method), escapeLabel(k.route), m.inFlight[k])
+	}
+}
+
+func (m *RequestMetrics) name(s string) string {
+	if m.namespace == "" {
+		return s
+	}
+	return m.namespace + "_" + s
+}
+
+func (k requestKey) labels() string {
+	return fmt.Sprintf("method=\"%s\",route=\"%s\",status=\"%s\"", escapeLabel(k.method), escapeLabel(k.route), escapeLabel(k.status))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,60 @@
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/best4tires/kit/log/logtest"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	logtest.Install(t)
+
+	m := NewRequestMetrics(WithBuckets(10, 0.5), WithNamespace("test"))
+	router := NewRouter()
+	router.GET("/foos/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	router.POST("/foos", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	h := router.Handler(m.Middleware())
+
+	for _, r := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/foos/1", nil),
+		httptest.NewRequest(http.MethodGet, "/foos/2", nil),
+		httptest.NewRequest(http.MethodPost, "/foos", nil),
+		httptest.NewRequest(http.MethodGet, "/bars", nil),
+		httptest.NewRequest(http.MethodDelete, "/foos", nil),
+	} {
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	resp := httptest.NewRecorder()
+	m.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := resp.Body.String()
+	for _, line := range []string{
+		"# TYPE test_http_requests_total counter",
+		`test_http_requests_total{method="POST",route="/foos",status="4xx"} 1`,
+		`test_http_requests_total{method="GET",route="/foos/{id}",status="2xx"} 2`,
+		`test_http_requests_total{method="GET",route="unmatched",status="4xx"} 1`,
+		`test_http_requests_total{method="DELETE",route="unmatched",status="4xx"} 1`,
+		"# TYPE test_http_request_duration_seconds histogram",
+		`test_http_request_duration_seconds_bucket{method="GET",route="/foos/{id}",status="2xx",le="0.5"} 2`,
+		`test_http_request_duration_seconds_bucket{method="GET",route="/foos/{id}",status="2xx",le="10"} 2`,
+		`test_http_request_duration_seconds_bucket{method="GET",route="/foos/{id}",status="2xx",le="+Inf"} 2`,
+		`test_http_request_duration_seconds_count{method="GET",route="/foos/{id}",status="2xx"} 2`,
+		"# TYPE test_http_requests_in_flight gauge",
+		`test_http_requests_in_flight{method="GET",route="/foos/{id}"} 0`,
+	} {
+		testutil.AssertEqual(t, true, strings.Contains(body, line+"\n"))
+	}
+	testutil.AssertEqual(t, false, strings.Contains(body, "/foos/1"))
+}
+
+func TestStatusClass(t *testing.T) {
+	testutil.AssertEqual(t, "2xx", statusClass(0))
+	testutil.AssertEqual(t, "3xx", statusClass(http.StatusNotModified))
+	testutil.AssertEqual(t, "5xx", statusClass(http.StatusBadGateway))
+}
@@ -1,6 +1,7 @@
 package srv
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httputil"
 	"time"
@@ -13,18 +14,22 @@ func GZIP() func(http.Handler) http.Handler {
 	return handlers.CompressHandler
 }
 
+// Logging logs an access entry for each request. The request context is amended with the method and route of the request,
+// so that handlers logging with log.FromContext inherit them.
 func Logging(dumpRequest bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = r.WithContext(log.WithContext(r.Context(), "method", r.Method, "route", RouteTemplate(r)))
 			sw := NewStatusWriter(w)
 			t0 := time.Now()
 			next.ServeHTTP(sw, r)
-			log.Accessf("%s host=%q path=%q query=%q => status %d (%s) in %s",
+			logger := log.FromContext(r.Context())
+			logger.Accessf("%s host=%q path=%q query=%q => status %d (%s) in %s",
 				r.Method, r.Host, r.URL.Path, r.URL.RawQuery, sw.statusCode, http.StatusText(sw.statusCode), time.Since(t0))
 
 			if dumpRequest {
 				bs, _ := httputil.DumpRequest(r, true)
-				log.Accessf("request:\n%s", string(bs))
+				logger.Accessf("request:\n%s", string(bs))
 			}
 		})
 	}
@@ -41,14 +46,18 @@ func Cors() func(http.Handler) http.Handler {
 	}
 }
 
+// Recovery recovers from panics in handlers, responds with an internal server error problem and logs the panic with its stack
 func Recovery() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
-				if err := recover(); err != nil {
-					w.WriteHeader(http.StatusInternalServerError)
-					log.Errorf("http-request: recovered: %v", err)
-					log.DebugStack()
+				if perr := recover(); perr != nil {
+					err, ok := perr.(error)
+					if !ok {
+						err = fmt.Errorf("%v", perr)
+					}
+					log.FromContext(r.Context()).Err(err).WithStack().Errorf("http-request: recovered: %v", perr)
+					WriteProblem(w, r, NewProblem(http.StatusInternalServerError, ""))
 				}
 			}()
 			next.ServeHTTP(w, r)
@@ -0,0 +1,52 @@
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/best4tires/kit/log"
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/log/logtest"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestRecovery(t *testing.T) {
+	rec := logtest.Install(t)
+
+	h := RequestID()(Recovery()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(HeaderRequestID, "req-1")
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, r)
+
+	testutil.AssertEqual(t, http.StatusInternalServerError, resp.Code)
+	testutil.AssertEqual(t, ContentTypeProblemJSON, resp.Header().Get(HeaderContentType))
+	testutil.AssertEqual(t, `{"instance":"/","request_id":"req-1","status":500,"title":"Internal Server Error","type":"about:blank"}`+"\n", resp.Body.String())
+	rec.AssertLogged(entry.LevelError, "recovered: boom")
+	testutil.AssertEqual(t, 1, len(rec.Entries()))
+
+	e := rec.Entries()[0]
+	id, _ := e.Fields.Value("request_id")
+	testutil.AssertEqual(t, "req-1", id)
+	_, ok := e.Fields.Value(log.FieldStack)
+	testutil.AssertEqual(t, true, ok)
+}
+
+func TestLoggingContextFields(t *testing.T) {
+	rec := logtest.Install(t)
+
+	router := NewRouter()
+	router.GET("/foos/{id}", func(w http.ResponseWriter, r *http.Request) {
+		log.FromContext(r.Context()).Infof("handling foo")
+	})
+	h := router.Handler(Logging(false))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/foos/42", nil))
+
+	e, ok := rec.Find(func(e entry.Entry) bool { return e.Message == "handling foo" })
+	testutil.AssertEqual(t, true, ok)
+	testutil.AssertEqual(t, entry.MakeFields("method", "GET", "route", "/foos/{id}"), e.Fields)
+	rec.AssertLogged(entry.LevelAccess, `path="/foos/42"`)
+}
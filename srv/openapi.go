@@ -0,0 +1,422 @@
+package srv
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification of generated documents
+const OpenAPIVersion = "3.1.0"
+
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+type OpenAPIComponents struct {
+	Schemas map[string]*OpenAPISchema `json:"schemas"`
+}
+
+type OpenAPIOperation struct {
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Deprecated  bool                        `json:"deprecated,omitempty"`
+	Parameters  []OpenAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+type OpenAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *OpenAPISchema `json:"schema"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties any                       `json:"additionalProperties,omitempty"`
+	Enum                 []any                     `json:"enum,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+}
+
+const (
+	problemSchemaName = "Problem"
+	contentTypeJSON   = "application/json"
+)
+
+var problemSchema = &OpenAPISchema{
+	Type: "object",
+	Properties: map[string]*OpenAPISchema{
+		"type":     {Type: "string", Format: "uri-reference"},
+		"title":    {Type: "string"},
+		"status":   {Type: "integer"},
+		"detail":   {Type: "string"},
+		"instance": {Type: "string", Format: "uri-reference"},
+	},
+	AdditionalProperties: true,
+}
+
+// OpenAPIHandler serves the OpenAPI document of the routes registered at the router
+func (r *Router) OpenAPIHandler(info OpenAPIInfo) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		WriteJSON(w, http.StatusOK, r.OpenAPI(info))
+	}
+}
+
+// OpenAPIHandler serves the OpenAPI document of the routes registered at the root router
+func (r *PrefixRouter) OpenAPIHandler(info OpenAPIInfo) http.HandlerFunc {
+	return r.router.OpenAPIHandler(info)
+}
+
+// OpenAPI builds an OpenAPI document of the registered routes. Schemas of named struct types are
+// added as components; json tags name the properties, validate tags add constraints.
+func (r *Router) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	gen := &schemaGen{
+		schemas: map[string]*OpenAPISchema{problemSchemaName: problemSchema},
+		names:   map[reflect.Type]string{},
+	}
+	doc := &OpenAPIDocument{
+		OpenAPI:    OpenAPIVersion,
+		Info:       info,
+		Paths:      map[string]map[string]*OpenAPIOperation{},
+		Components: OpenAPIComponents{Schemas: gen.schemas},
+	}
+	for _, ri := range r.Routes() {
+		path := openAPIPath(ri.Pattern)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*OpenAPIOperation{}
+		}
+		doc.Paths[path][strings.ToLower(ri.Method)] = gen.operation(ri)
+	}
+	return doc
+}
+
+// openAPIPath converts a mux pattern into an OpenAPI path, e.g. "foos/{id:[0-9]+}" => "/foos/{id}"
+func openAPIPath(pattern string) string {
+	p := routeVarRx.ReplaceAllString(pattern, "{$1}")
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
+
+type schemaGen struct {
+	schemas map[string]*OpenAPISchema
+	names   map[reflect.Type]string
+}
+
+func (g *schemaGen) operation(ri RouteInfo) *OpenAPIOperation {
+	op := &OpenAPIOperation{
+		Summary:     ri.Summary,
+		Description: ri.Description,
+		Tags:        ri.Tags,
+		Deprecated:  ri.Deprecated,
+		Responses:   map[string]*OpenAPIResponse{},
+	}
+
+	// parameters
+	pathTypes := map[string]reflect.Type{}
+	var queryParams []OpenAPIParameter
+	var bodyFields int
+	if st := structType(ri.Request); st != nil {
+		for _, sf := range visibleFields(st) {
+			if name, ok := sf.Tag.Lookup("path"); ok {
+				pathTypes[name] = sf.Type
+			} else if name, ok := sf.Tag.Lookup("query"); ok {
+				p := OpenAPIParameter{Name: name, In: "query", Schema: g.schemaOf(sf.Type)}
+				applyRules(p.Schema, sf.Tag.Get("validate"), func() { p.Required = true })
+				queryParams = append(queryParams, p)
+			} else if jsonName(sf) != "" {
+				bodyFields++
+			}
+		}
+	}
+	for _, v := range ri.Vars {
+		schema := &OpenAPISchema{Type: "string"}
+		if t, ok := pathTypes[v]; ok {
+			schema = g.schemaOf(t)
+		}
+		op.Parameters = append(op.Parameters, OpenAPIParameter{Name: v, In: "path", Required: true, Schema: schema})
+	}
+	op.Parameters = append(op.Parameters, queryParams...)
+	if ri.MetaQuery {
+		op.Parameters = append(op.Parameters,
+			OpenAPIParameter{Name: "limit", In: "query", Schema: &OpenAPISchema{Type: "integer"}},
+			OpenAPIParameter{Name: "skip", In: "query", Schema: &OpenAPISchema{Type: "integer"}},
+			OpenAPIParameter{Name: "filter", In: "query", Description: "name,comparator,value with comparator eq, ls, gt or like",
+				Schema: &OpenAPISchema{Type: "array", Items: &OpenAPISchema{Type: "string"}}},
+			OpenAPIParameter{Name: "sort", In: "query", Description: "name:ASC|DESC,...", Schema: &OpenAPISchema{Type: "string"}},
+		)
+	}
+
+	// request body
+	hasBody := ri.Method != http.MethodGet && ri.Method != http.MethodHead && ri.Method != http.MethodDelete
+	if ri.Request != nil && hasBody && (structType(ri.Request) == nil || bodyFields > 0) {
+		op.RequestBody = &OpenAPIRequestBody{
+			Required: true,
+			Content:  map[string]OpenAPIMediaType{contentTypeJSON: {Schema: g.schemaOf(ri.Request)}},
+		}
+	}
+
+	// responses
+	for status, t := range ri.Responses {
+		resp := &OpenAPIResponse{Description: http.StatusText(status)}
+		if t != nil {
+			resp.Content = map[string]OpenAPIMediaType{contentTypeJSON: {Schema: g.schemaOf(t)}}
+		}
+		op.Responses[strconv.Itoa(status)] = resp
+	}
+	if len(op.Responses) == 0 {
+		op.Responses[strconv.Itoa(http.StatusOK)] = &OpenAPIResponse{Description: http.StatusText(http.StatusOK)}
+	}
+	op.Responses["default"] = &OpenAPIResponse{
+		Description: "Error",
+		Content: map[string]OpenAPIMediaType{
+			ContentTypeProblemJSON: {Schema: &OpenAPISchema{Ref: "#/components/schemas/" + problemSchemaName}},
+		},
+	}
+	return op
+}
+
+// structType returns the struct type t (or the type t points to) is, or nil
+func structType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// visibleFields returns the exported fields of st, flattening embedded structs without json name
+func visibleFields(st reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if sf.Anonymous {
+			if et := structType(sf.Type); et != nil {
+				if n, _, _ := strings.Cut(sf.Tag.Get("json"), ","); n == "" {
+					fields = append(fields, visibleFields(et)...)
+					continue
+				}
+			}
+		}
+		if sf.IsExported() {
+			fields = append(fields, sf)
+		}
+	}
+	return fields
+}
+
+// jsonName returns the name of the field in json, or an empty string, if the field is not part of the json body
+func jsonName(sf reflect.StructField) string {
+	if _, ok := sf.Tag.Lookup("path"); ok {
+		return ""
+	}
+	if _, ok := sf.Tag.Lookup("query"); ok {
+		return ""
+	}
+	if sf.Tag.Get("json") == "-" {
+		return ""
+	}
+	return fieldName(sf)
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	schemaNameRx      = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+func (g *schemaGen) schemaOf(t reflect.Type) *OpenAPISchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &OpenAPISchema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &OpenAPISchema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &OpenAPISchema{Type: "integer", Minimum: &zero}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &OpenAPISchema{Ref: "#/components/schemas/" + g.component(t)}
+	default:
+		return &OpenAPISchema{}
+	}
+}
+
+// component registers the schema of the named struct type t and returns its name
+func (g *schemaGen) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := schemaNameRx.ReplaceAllString(t.Name(), "_")
+	if _, taken := g.schemas[name]; taken {
+		pkg := t.PkgPath()
+		name = schemaNameRx.ReplaceAllString(pkg[strings.LastIndex(pkg, "/")+1:], "_") + "." + name
+	}
+	base := name
+	for i := 2; ; i++ {
+		if _, taken := g.schemas[name]; !taken {
+			break
+		}
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	g.names[t] = name
+	// register before building to support recursive types
+	g.schemas[name] = &OpenAPISchema{}
+	*g.schemas[name] = *g.structSchema(t)
+	return name
+}
+
+func (g *schemaGen) structSchema(t reflect.Type) *OpenAPISchema {
+	s := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+	for _, sf := range visibleFields(t) {
+		name := jsonName(sf)
+		if name == "" {
+			continue
+		}
+		ps := g.schemaOf(sf.Type)
+		if ps.Ref != "" {
+			// constraints can't be added to references
+			if strings.Contains(sf.Tag.Get("validate"), "required") {
+				s.Required = append(s.Required, name)
+			}
+		} else {
+			applyRules(ps, sf.Tag.Get("validate"), func() { s.Required = append(s.Required, name) })
+		}
+		s.Properties[name] = ps
+	}
+	return s
+}
+
+// applyRules adds the constraints of the validate rules (see Validate) to s
+func applyRules(s *OpenAPISchema, tag string, required func()) {
+	for _, rule := range splitRules(tag) {
+		key, arg, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required()
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			applyBound(s, key, n)
+		case "oneof":
+			for _, o := range strings.Fields(arg) {
+				var v any = o
+				if s.Type == "integer" || s.Type == "number" {
+					if f, err := strconv.ParseFloat(o, 64); err == nil {
+						v = f
+					}
+				}
+				s.Enum = append(s.Enum, v)
+			}
+		case "email":
+			s.Format = "email"
+		case "regex":
+			s.Pattern = arg
+		}
+	}
+}
+
+func applyBound(s *OpenAPISchema, key string, n float64) {
+	i := int(n)
+	switch s.Type {
+	case "string":
+		if key != "max" {
+			s.MinLength = &i
+		}
+		if key != "min" {
+			s.MaxLength = &i
+		}
+	case "array":
+		if key != "max" {
+			s.MinItems = &i
+		}
+		if key != "min" {
+			s.MaxItems = &i
+		}
+	case "integer", "number":
+		switch key {
+		case "min":
+			s.Minimum = &n
+		case "max":
+			s.Maximum = &n
+		}
+	}
+}
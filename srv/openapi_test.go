@@ -0,0 +1,103 @@
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/best4tires/kit/log/logtest"
+	"github.com/best4tires/kit/testutil"
+)
+
+type apiFoo struct {
+	ID     int       `json:"id"`
+	Name   string    `json:"name" validate:"required,max=10"`
+	Kind   string    `json:"kind,omitempty" validate:"omitempty,oneof=a b"`
+	Parent *apiFoo   `json:"parent,omitempty"`
+	Tags   []string  `json:"tags"`
+	Secret string    `json:"-"`
+	Extra  apiExtras `json:"extra"`
+}
+
+type apiExtras map[string]int
+
+type apiUpdateFoo struct {
+	ID     int    `path:"id"`
+	DryRun bool   `query:"dry_run"`
+	Name   string `json:"name" validate:"required"`
+}
+
+func TestRoutes(t *testing.T) {
+	logtest.Install(t)
+
+	router := NewRouter()
+	pr := router.WithPrefix("/api/")
+	pr.GET("foos/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {}, Summary("get a foo"), IgnoreTrailingSlashes{})
+	pr.PUT("foos/{id}", func(w http.ResponseWriter, r *http.Request) {}, Tags{"foos"}, Deprecated{})
+
+	testutil.AssertEqual(t, 2, len(pr.Routes()))
+	ri := pr.Routes()[0]
+	testutil.AssertEqual(t, http.MethodGet, ri.Method)
+	testutil.AssertEqual(t, "/api/foos/{id:[0-9]+}", ri.Pattern)
+	testutil.AssertEqual(t, []string{"id"}, ri.Vars)
+	testutil.AssertEqual(t, "get a foo", ri.Summary)
+	testutil.AssertEqual(t, true, pr.Routes()[1].Deprecated)
+}
+
+func TestOpenAPI(t *testing.T) {
+	logtest.Install(t)
+
+	router := NewRouter()
+	router.GET("/foos", func(w http.ResponseWriter, r *http.Request) {},
+		Summary("list foos"), MetaQuery{}, ResponseType{Status: http.StatusOK, Value: []apiFoo{}})
+	router.PUT("/foos/{id}", func(w http.ResponseWriter, r *http.Request) {},
+		RequestType{Value: apiUpdateFoo{}}, ResponseType{Status: http.StatusOK, Value: apiFoo{}}, ResponseType{Status: http.StatusNoContent})
+	router.GET("/openapi.json", router.OpenAPIHandler(OpenAPIInfo{Title: "foos", Version: "1.0.0"}), Undocumented{})
+
+	resp := httptest.NewRecorder()
+	router.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	testutil.AssertEqual(t, http.StatusOK, resp.Code)
+
+	var doc map[string]any
+	testutil.AssertNoErr(t, json.NewDecoder(resp.Body).Decode(&doc), "decode")
+	testutil.AssertEqual(t, "3.1.0", doc["openapi"])
+	testutil.AssertEqual(t, map[string]any{"title": "foos", "version": "1.0.0"}, doc["info"])
+
+	paths := doc["paths"].(map[string]any)
+	testutil.AssertEqual(t, 2, len(paths))
+	list := paths["/foos"].(map[string]any)["get"].(map[string]any)
+	testutil.AssertEqual(t, "list foos", list["summary"])
+	testutil.AssertEqual(t, 4, len(list["parameters"].([]any)))
+	testutil.AssertEqual(t, map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/apiFoo"}},
+		list["responses"].(map[string]any)["200"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"])
+
+	update := paths["/foos/{id}"].(map[string]any)["put"].(map[string]any)
+	testutil.AssertEqual(t, []any{
+		map[string]any{"name": "id", "in": "path", "required": true, "schema": map[string]any{"type": "integer", "format": "int64"}},
+		map[string]any{"name": "dry_run", "in": "query", "schema": map[string]any{"type": "boolean"}},
+	}, update["parameters"])
+	testutil.AssertEqual(t, map[string]any{"description": "No Content"}, update["responses"].(map[string]any)["204"])
+	testutil.AssertEqual(t, true, update["requestBody"] != nil)
+	testutil.AssertEqual(t, true, update["responses"].(map[string]any)["default"] != nil)
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	testutil.AssertEqual(t, map[string]any{
+		"type":     "object",
+		"required": []any{"name"},
+		"properties": map[string]any{
+			"id":     map[string]any{"type": "integer", "format": "int64"},
+			"name":   map[string]any{"type": "string", "maxLength": float64(10)},
+			"kind":   map[string]any{"type": "string", "enum": []any{"a", "b"}},
+			"parent": map[string]any{"$ref": "#/components/schemas/apiFoo"},
+			"tags":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"extra":  map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "integer", "format": "int64"}},
+		},
+	}, schemas["apiFoo"])
+	testutil.AssertEqual(t, map[string]any{
+		"type":       "object",
+		"required":   []any{"name"},
+		"properties": map[string]any{"name": map[string]any{"type": "string"}},
+	}, schemas["apiUpdateFoo"])
+	testutil.AssertEqual(t, true, schemas["Problem"] != nil)
+}
@@ -0,0 +1,205 @@
+package srv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/best4tires/kit/errs"
+	"github.com/best4tires/kit/log"
+)
+
+// ContentTypeProblemJSON is the content type of problem details (RFC 9457)
+const ContentTypeProblemJSON = "application/problem+json"
+
+// ProblemTypeBlank is the default problem type, meaning the problem has no semantics beyond its status code
+const ProblemTypeBlank = "about:blank"
+
+// ProblemFieldErrors is the extension member carrying field errors
+const ProblemFieldErrors = "errors"
+
+var problemMembers = map[string]bool{"type": true, "title": true, "status": true, "detail": true, "instance": true}
+
+// Problem are problem details according to RFC 9457. Extensions are marshalled as additional top-level members.
+// A Problem is an error, which unwraps to the errs error matching its status.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+// NewProblem creates a problem of type about:blank for the status
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   ProblemTypeBlank,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// ProblemFromError converts err into a problem. Problems in the chain of err are returned as they are,
+// other errors get the status given by ErrorStatus and their field errors as extension member "errors".
+// The detail of internal errors is not exposed.
+func ProblemFromError(err error) *Problem {
+	var p *Problem
+	if errors.As(err, &p) {
+		return p
+	}
+	status := ErrorStatus(err)
+	if status >= http.StatusInternalServerError {
+		return NewProblem(status, "")
+	}
+	p = NewProblem(status, err.Error())
+	if fields := errs.FieldsOf(err); len(fields) > 0 {
+		p = p.With(ProblemFieldErrors, fields)
+	}
+	return p
+}
+
+// With returns a copy of p with the extension member set
+func (p *Problem) With(key string, value any) *Problem {
+	cp := *p
+	cp.Extensions = make(map[string]any, len(p.Extensions)+1)
+	for k, v := range p.Extensions {
+		cp.Extensions[k] = v
+	}
+	cp.Extensions[key] = value
+	return &cp
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return fmt.Sprintf("%d %s", p.Status, p.Title)
+	}
+	return fmt.Sprintf("%d %s: %s", p.Status, p.Title, p.Detail)
+}
+
+// Unwrap returns the errs error matching the status of the problem, carrying the field errors
+// of the extension member "errors", if any
+func (p *Problem) Unwrap() error {
+	err := errs.FromHTTPStatus(p.Status)
+	var e *errs.Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	switch fields := p.Extensions[ProblemFieldErrors].(type) {
+	case []errs.FieldError:
+		for _, f := range fields {
+			e = e.WithField(f.Field, f.Message)
+		}
+	case []any:
+		// decoded from json
+		for _, f := range fields {
+			m, _ := f.(map[string]any)
+			field, _ := m["field"].(string)
+			msg, _ := m["message"].(string)
+			if field != "" {
+				e = e.WithField(field, msg)
+			}
+		}
+	}
+	return e
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		if !problemMembers[k] {
+			m[k] = v
+		}
+	}
+	m["type"] = p.Type
+	if p.Type == "" {
+		m["type"] = ProblemTypeBlank
+	}
+	if p.Title != "" {
+		m["title"] = p.Title
+	}
+	if p.Status != 0 {
+		m["status"] = p.Status
+	}
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	}
+	return json.Marshal(m)
+}
+
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*p = Problem{}
+	for k, raw := range m {
+		var err error
+		switch k {
+		case "type":
+			err = json.Unmarshal(raw, &p.Type)
+		case "title":
+			err = json.Unmarshal(raw, &p.Title)
+		case "status":
+			err = json.Unmarshal(raw, &p.Status)
+		case "detail":
+			err = json.Unmarshal(raw, &p.Detail)
+		case "instance":
+			err = json.Unmarshal(raw, &p.Instance)
+		default:
+			var v any
+			err = json.Unmarshal(raw, &v)
+			if p.Extensions == nil {
+				p.Extensions = map[string]any{}
+			}
+			p.Extensions[k] = v
+		}
+		if err != nil {
+			return fmt.Errorf("problem member %q: %w", k, err)
+		}
+	}
+	if p.Type == "" {
+		p.Type = ProblemTypeBlank
+	}
+	return nil
+}
+
+// WriteProblem writes p as application/problem+json. The instance defaults to the request path
+// and the request id is added as extension member "request_id".
+func WriteProblem(w http.ResponseWriter, r *http.Request, p *Problem) {
+	if p.Instance == "" || p.Status == 0 {
+		cp := *p
+		if cp.Instance == "" {
+			cp.Instance = r.URL.Path
+		}
+		if cp.Status == 0 {
+			cp.Status = http.StatusInternalServerError
+		}
+		p = &cp
+	}
+	id := RequestIDFromContext(r.Context())
+	if id == "" {
+		id = r.Header.Get(HeaderRequestID)
+	}
+	if _, ok := p.Extensions["request_id"]; !ok && id != "" {
+		p = p.With("request_id", id)
+	}
+	w.Header().Set(HeaderContentType, ContentTypeProblemJSON)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// WriteError writes err as problem with the status given by ErrorStatus.
+// Internal errors are logged and their message is not exposed to the client.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	p := ProblemFromError(err)
+	if p.Status >= http.StatusInternalServerError {
+		log.FromContext(r.Context()).Err(err).Errorf("%s %s: %v", r.Method, r.URL.Path, err)
+	}
+	WriteProblem(w, r, p)
+}
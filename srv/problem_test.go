@@ -0,0 +1,94 @@
+package srv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/best4tires/kit/errs"
+	"github.com/best4tires/kit/log/logtest"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestProblemJSON(t *testing.T) {
+	p := NewProblem(http.StatusConflict, "foo 42 exists").With("foo_id", "42").With("status", 1)
+	p.Type = "https://example.com/problems/exists"
+	bs, err := json.Marshal(p)
+	testutil.AssertNoErr(t, err, "marshal")
+	testutil.AssertEqual(t, `{"detail":"foo 42 exists","foo_id":"42","status":409,"title":"Conflict","type":"https://example.com/problems/exists"}`, string(bs))
+
+	var have Problem
+	testutil.AssertNoErr(t, json.Unmarshal(bs, &have), "unmarshal")
+	testutil.AssertEqual(t, Problem{
+		Type:       "https://example.com/problems/exists",
+		Title:      "Conflict",
+		Status:     http.StatusConflict,
+		Detail:     "foo 42 exists",
+		Extensions: map[string]any{"foo_id": "42"},
+	}, have)
+}
+
+func TestProblemErrs(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		detail string
+	}{
+		{err: fmt.Errorf("foo 1: %w", errs.NotFound()), status: http.StatusNotFound, detail: "foo 1: not found"},
+		{err: errs.BadArgs(), status: http.StatusBadRequest, detail: "bad arguments"},
+		{err: errs.NotAuthenticated(), status: http.StatusUnauthorized, detail: "not authenticated"},
+		{err: errs.Forbidden(), status: http.StatusForbidden, detail: "forbidden"},
+		{err: errors.New("secret"), status: http.StatusInternalServerError},
+		{err: fmt.Errorf("wrapped: %w", NewProblem(http.StatusConflict, "exists")), status: http.StatusConflict, detail: "exists"},
+	}
+	for _, test := range tests {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			p := ProblemFromError(test.err)
+			testutil.AssertEqual(t, test.status, p.Status)
+			testutil.AssertEqual(t, test.detail, p.Detail)
+			testutil.AssertEqual(t, test.status, ErrorStatus(p))
+		})
+	}
+
+	testutil.AssertEqual(t, true, errors.Is(NewProblem(http.StatusNotFound, ""), errs.NotFound()))
+	testutil.AssertEqual(t, true, errors.Is(NewProblem(http.StatusForbidden, ""), errs.Forbidden()))
+	testutil.AssertEqual(t, false, errors.Is(NewProblem(http.StatusConflict, ""), errs.BadArgs()))
+
+	p := ProblemFromError(errs.New(errs.CodeBadArgs, "").WithField("name", "required"))
+	testutil.AssertEqual(t, []errs.FieldError{{Field: "name", Message: "required"}}, p.Extensions[ProblemFieldErrors])
+	testutil.AssertEqual(t, []errs.FieldError{{Field: "name", Message: "required"}}, errs.FieldsOf(p))
+}
+
+func TestRouterProblems(t *testing.T) {
+	logtest.Install(t)
+
+	router := NewRouter()
+	router.GET("/foos", func(w http.ResponseWriter, r *http.Request) {})
+	h := router.Handler()
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{method: http.MethodGet, path: "/bars", status: http.StatusNotFound},
+		{method: http.MethodDelete, path: "/foos", status: http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.path, nil)
+		r.Header.Set(HeaderRequestID, "req-1")
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, r)
+		testutil.AssertEqual(t, test.status, resp.Code)
+		testutil.AssertEqual(t, ContentTypeProblemJSON, resp.Header().Get(HeaderContentType))
+
+		var p Problem
+		testutil.AssertNoErr(t, json.NewDecoder(resp.Body).Decode(&p), "decode")
+		testutil.AssertEqual(t, test.status, p.Status)
+		testutil.AssertEqual(t, test.path, p.Instance)
+		testutil.AssertEqual(t, "req-1", p.Extensions["request_id"])
+	}
+}
@@ -0,0 +1,59 @@
+package srv
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/best4tires/kit/log"
+	"github.com/best4tires/kit/uuid"
+)
+
+const maxRequestIDLength = 128
+
+type requestIDCtxKey struct{}
+
+// RequestID reads the request id from the X-Request-ID header or generates a new one, if missing or invalid.
+// The id is echoed in the response header and stored in the request context, where it is picked up by log.FromContext
+// and forwarded by the req package.
+func RequestID() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(HeaderRequestID)
+			if !validRequestID(id) {
+				var err error
+				id, err = uuid.MakeV4()
+				if err != nil {
+					log.Warnf("request-id: make-v4: %v", err)
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			w.Header().Set(HeaderRequestID, id)
+			next.ServeHTTP(w, r.WithContext(ContextWithRequestID(r.Context(), id)))
+		})
+	}
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the passed request id, also as "request_id" log field
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDCtxKey{}, id)
+	return log.WithContext(ctx, "request_id", id)
+}
+
+// RequestIDFromContext returns the request id carried by ctx or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/best4tires/kit/log"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		generate bool
+	}{
+		{name: "passed", header: "abc-123"},
+		{name: "missing", header: "", generate: true},
+		{name: "invalid", header: "with space", generate: true},
+		{name: "too long", header: strings.Repeat("x", maxRequestIDLength+1), generate: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ctxID string
+			var fieldID any
+			h := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctxID = RequestIDFromContext(r.Context())
+				fieldID, _ = log.ContextFields(r.Context()).Value("request_id")
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.header != "" {
+				r.Header.Set(HeaderRequestID, test.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			respID := rec.Header().Get(HeaderRequestID)
+			if test.generate {
+				testutil.AssertEqual(t, 36, len(respID))
+			} else {
+				testutil.AssertEqual(t, test.header, respID)
+			}
+			testutil.AssertEqual(t, respID, ctxID)
+			testutil.AssertEqual(t, respID, fieldID)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package srv
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RouteTemplate returns the pattern of the route matched for the request, e.g. "/api/foos/{id}".
+// It returns an empty string, if no route has been matched (yet).
+func RouteTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	tpl, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return tpl
+}
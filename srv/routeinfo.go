@@ -0,0 +1,120 @@
+package srv
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+)
+
+// Route options describing a route for the OpenAPI document, e.g.
+//
+//	router.GET("foos/{id}", h, srv.Summary("get a foo"), srv.ResponseType{Status: 200, Value: Foo{}})
+
+// Summary is a short summary of the route
+type Summary string
+
+// Description is a verbose description of the route
+type Description string
+
+// Tags group routes
+type Tags []string
+
+// Deprecated marks the route as deprecated
+type Deprecated struct{}
+
+// Undocumented excludes the route from Routes and thus from the OpenAPI document, e.g. for infrastructure routes
+type Undocumented struct{}
+
+// MetaQuery marks the route as supporting the query parameters parsed by ParseMeta
+type MetaQuery struct{}
+
+// RequestType declares the type of the request. Fields tagged with `path` and `query` are documented as parameters,
+// the fields marshalled to json as request body.
+type RequestType struct {
+	Value any
+}
+
+// ResponseType declares the type of the response body for a status. A nil value documents a response without body.
+type ResponseType struct {
+	Status int
+	Value  any
+}
+
+// RouteInfo describes a registered route
+type RouteInfo struct {
+	Method      string
+	Pattern     string
+	Vars        []string
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+	MetaQuery   bool
+	Request     reflect.Type
+	Responses   map[int]reflect.Type
+}
+
+var routeVarRx = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// routeVars returns the names of the variables of a mux pattern, e.g. "/foos/{id:[0-9]+}" => ["id"]
+func routeVars(pattern string) []string {
+	var vars []string
+	for _, m := range routeVarRx.FindAllStringSubmatch(pattern, -1) {
+		vars = append(vars, m[1])
+	}
+	return vars
+}
+
+func typeOf(v any) reflect.Type {
+	if v == nil {
+		return nil
+	}
+	return reflect.TypeOf(v)
+}
+
+func (r *Router) record(method, pattern string, options []interface{}) {
+	if r.containsOption(options, Undocumented{}) {
+		return
+	}
+	ri := RouteInfo{
+		Method:    method,
+		Pattern:   pattern,
+		Vars:      routeVars(pattern),
+		Responses: map[int]reflect.Type{},
+	}
+	for _, o := range options {
+		switch o := o.(type) {
+		case Summary:
+			ri.Summary = string(o)
+		case Description:
+			ri.Description = string(o)
+		case Tags:
+			ri.Tags = append(ri.Tags, o...)
+		case Deprecated:
+			ri.Deprecated = true
+		case MetaQuery:
+			ri.MetaQuery = true
+		case RequestType:
+			ri.Request = typeOf(o.Value)
+		case ResponseType:
+			ri.Responses[o.Status] = typeOf(o.Value)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, ri)
+}
+
+// Routes returns the registered routes ordered by pattern and method. Prefix routes and undocumented routes are not recorded.
+func (r *Router) Routes() []RouteInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	routes := append([]RouteInfo{}, r.routes...)
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/best4tires/kit/log"
 	"github.com/gorilla/mux"
@@ -20,17 +21,32 @@ func Var(r *http.Request, key string) string {
 // Router encapsulates a http router
 type Router struct {
 	mux *mux.Router
+	// unwrapped not-found and method-not-allowed handlers and the middlewares applied to them
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+	mwares           []mux.MiddlewareFunc
+
+	mu     sync.Mutex
+	routes []RouteInfo
 }
 
 // NewRouter creates a new router
 func NewRouter() *Router {
 	mr := mux.NewRouter()
-	mr.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Warnf("not-found: %q", r.URL.String())
-		http.Error(w, fmt.Sprintf("not-found %q", r.URL.Path), http.StatusNotFound)
+	notFound := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.FromContext(r.Context()).Warnf("not-found: %q", r.URL.String())
+		WriteProblem(w, r, NewProblem(http.StatusNotFound, fmt.Sprintf("no route for %q", r.URL.Path)))
+	})
+	methodNotAllowed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.FromContext(r.Context()).Warnf("method-not-allowed: %s %q", r.Method, r.URL.String())
+		WriteProblem(w, r, NewProblem(http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed for %q", r.Method, r.URL.Path)))
 	})
+	mr.NotFoundHandler = notFound
+	mr.MethodNotAllowedHandler = methodNotAllowed
 	r := &Router{
-		mux: mr,
+		mux:              mr,
+		notFound:         notFound,
+		methodNotAllowed: methodNotAllowed,
 	}
 	return r
 }
@@ -43,9 +59,20 @@ func (r *Router) WithPrefix(prefix string) *PrefixRouter {
 	}
 }
 
-// Handler returns the http handler of the router
+// Handler returns the http handler of the router. The middlewares apply to the not-found and
+// method-not-allowed handlers as well, since mux runs its middlewares for matched routes only.
 func (r *Router) Handler(mwares ...mux.MiddlewareFunc) http.Handler {
 	r.mux.Use(mwares...)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mwares = append(r.mwares, mwares...)
+	notFound, methodNotAllowed := r.notFound, r.methodNotAllowed
+	for i := len(r.mwares) - 1; i >= 0; i-- {
+		notFound = r.mwares[i](notFound)
+		methodNotAllowed = r.mwares[i](methodNotAllowed)
+	}
+	r.mux.NotFoundHandler = notFound
+	r.mux.MethodNotAllowedHandler = methodNotAllowed
 	return r.mux
 }
 
@@ -70,41 +97,34 @@ func (r *Router) patterns(s string, options ...interface{}) []string {
 	return ps
 }
 
-// GET registers a GET handler
-func (r *Router) GET(pattern string, handle http.HandlerFunc, options ...interface{}) {
+func (r *Router) handle(method string, pattern string, handle http.HandlerFunc, options []interface{}) {
+	r.record(method, pattern, options)
 	for _, p := range r.patterns(pattern, options...) {
-		log.Infof("route GET %q", p)
-		r.mux.Handle(p, handle).Methods("GET")
+		log.Infof("route %s %q", method, p)
+		r.mux.Handle(p, handle).Methods(method)
 	}
 }
 
+// GET registers a GET handler
+func (r *Router) GET(pattern string, handle http.HandlerFunc, options ...interface{}) {
+	r.handle(http.MethodGet, pattern, handle, options)
+}
+
 // POST registers a POST handler
 func (r *Router) POST(pattern string, handle http.HandlerFunc, options ...interface{}) {
-	for _, p := range r.patterns(pattern, options...) {
-		log.Infof("route POST %q", p)
-		r.mux.Handle(p, handle).Methods("POST")
-	}
+	r.handle(http.MethodPost, pattern, handle, options)
 }
 
 func (r *Router) PUT(pattern string, handle http.HandlerFunc, options ...interface{}) {
-	for _, p := range r.patterns(pattern, options...) {
-		log.Infof("route PUT %q", p)
-		r.mux.Handle(p, handle).Methods("PUT")
-	}
+	r.handle(http.MethodPut, pattern, handle, options)
 }
 
 func (r *Router) DELETE(pattern string, handle http.HandlerFunc, options ...interface{}) {
-	for _, p := range r.patterns(pattern, options...) {
-		log.Infof("route DELETE %q", p)
-		r.mux.Handle(p, handle).Methods("DELETE")
-	}
+	r.handle(http.MethodDelete, pattern, handle, options)
 }
 
 func (r *Router) HEAD(pattern string, handle http.HandlerFunc, options ...interface{}) {
-	for _, p := range r.patterns(pattern, options...) {
-		log.Infof("route HEAD %q", p)
-		r.mux.Handle(p, handle).Methods("HEAD")
-	}
+	r.handle(http.MethodHead, pattern, handle, options)
 }
 
 // PrefixGET registers a GET handler, which matches all routes with the given prefix
@@ -194,6 +214,10 @@ func (r *PrefixRouter) Prefix() string {
 }
 
 func (r *PrefixRouter) Handler(mwares ...mux.MiddlewareFunc) http.Handler {
-	r.router.mux.Use(mwares...)
-	return r.router.mux
+	return r.router.Handler(mwares...)
+}
+
+// Routes returns the routes registered at the root router
+func (r *PrefixRouter) Routes() []RouteInfo {
+	return r.router.Routes()
 }
@@ -0,0 +1,80 @@
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/log/logtest"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestRouterNotFound(t *testing.T) {
+	rec := logtest.Install(t)
+
+	router := NewRouter()
+	router.GET("/foos", func(w http.ResponseWriter, r *http.Request) {})
+	resp := httptest.NewRecorder()
+	router.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/bars", nil))
+
+	testutil.AssertEqual(t, http.StatusNotFound, resp.Code)
+	rec.AssertLogged(entry.LevelWarn, `not-found: "/bars"`)
+}
+
+func TestRouterUnmatchedRequestID(t *testing.T) {
+	rec := logtest.Install(t)
+
+	router := NewRouter()
+	router.GET("/foos", func(w http.ResponseWriter, r *http.Request) {})
+	h := router.Handler(RequestID())
+	for _, test := range []struct {
+		method string
+		path   string
+		status int
+		msg    string
+	}{
+		{method: http.MethodGet, path: "/bars", status: http.StatusNotFound, msg: `not-found: "/bars"`},
+		{method: http.MethodDelete, path: "/foos", status: http.StatusMethodNotAllowed, msg: `method-not-allowed: DELETE "/foos"`},
+	} {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		req.Header.Set(HeaderRequestID, "req-42")
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+
+		testutil.AssertEqual(t, test.status, resp.Code)
+		var p Problem
+		testutil.AssertNoErr(t, json.NewDecoder(resp.Body).Decode(&p), "decode")
+		testutil.AssertEqual(t, "req-42", p.Extensions["request_id"])
+		e, ok := rec.Find(func(e entry.Entry) bool { return e.Message == test.msg })
+		testutil.AssertEqual(t, true, ok)
+		id, _ := e.Fields.Value("request_id")
+		testutil.AssertEqual(t, "req-42", id)
+	}
+}
+
+func TestRouterHandlerTwice(t *testing.T) {
+	logtest.Install(t)
+
+	var calls []string
+	mware := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	router := NewRouter()
+	router.GET("/foos", func(w http.ResponseWriter, r *http.Request) {})
+	router.WithPrefix("/api/").Handler(mware("a"))
+	h := router.Handler(mware("b"))
+	router.Handler()
+
+	for _, path := range []string{"/foos", "/bars"} {
+		calls = nil
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		testutil.AssertEqual(t, []string{"a", "b"}, calls)
+	}
+}
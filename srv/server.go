@@ -43,8 +43,9 @@ func (s *Server) Close() {
 	s.server.Close()
 }
 
-func (s *Server) Shutdown(timeout time.Duration) {
+// Shutdown gracefully shuts down the server, waiting at most timeout for active connections
+func (s *Server) Shutdown(timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	s.server.Shutdown(ctx)
+	return s.server.Shutdown(ctx)
 }
@@ -0,0 +1,111 @@
+package srv
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/best4tires/kit/log"
+)
+
+// TraceParent holds the W3C trace context as passed by the traceparent header
+type TraceParent struct {
+	Version string
+	TraceID string
+	SpanID  string
+	Flags   string
+}
+
+// ParseTraceParent parses a traceparent header value like "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+func ParseTraceParent(s string) (TraceParent, error) {
+	sl := strings.Split(strings.TrimSpace(s), "-")
+	if len(sl) < 4 {
+		return TraceParent{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+	tp := TraceParent{
+		Version: sl[0],
+		TraceID: sl[1],
+		SpanID:  sl[2],
+		Flags:   sl[3],
+	}
+	switch {
+	case !isLowerHex(tp.Version, 2) || tp.Version == "ff":
+		return TraceParent{}, fmt.Errorf("invalid traceparent version %q", tp.Version)
+	case tp.Version == "00" && len(sl) != 4:
+		return TraceParent{}, fmt.Errorf("invalid traceparent %q", s)
+	case !isLowerHex(tp.TraceID, 32) || tp.TraceID == strings.Repeat("0", 32):
+		return TraceParent{}, fmt.Errorf("invalid trace-id %q", tp.TraceID)
+	case !isLowerHex(tp.SpanID, 16) || tp.SpanID == strings.Repeat("0", 16):
+		return TraceParent{}, fmt.Errorf("invalid parent-id %q", tp.SpanID)
+	case !isLowerHex(tp.Flags, 2):
+		return TraceParent{}, fmt.Errorf("invalid trace-flags %q", tp.Flags)
+	}
+	return tp, nil
+}
+
+func isLowerHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the trace parent as header value
+func (tp TraceParent) String() string {
+	return fmt.Sprintf("%s-%s-%s-%s", tp.Version, tp.TraceID, tp.SpanID, tp.Flags)
+}
+
+// Sampled reports, if the sampled flag is set
+func (tp TraceParent) Sampled() bool {
+	b, err := hex.DecodeString(tp.Flags)
+	return err == nil && len(b) == 1 && b[0]&0x01 == 0x01
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type traceParentCtxKey struct{}
+
+// TraceContext parses the traceparent header of incoming requests. The request gets a new span id as child of the
+// passed parent, or a new trace, if the header is missing or invalid. Trace and span id are stored in the request
+// context, where they are picked up by log.FromContext and forwarded by the req package.
+func TraceContext() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tp, err := ParseTraceParent(r.Header.Get(HeaderTraceParent))
+			if err != nil {
+				tp = TraceParent{
+					Version: "00",
+					TraceID: randomHex(16),
+					Flags:   "00",
+				}
+			}
+			tp.Version = "00"
+			tp.SpanID = randomHex(8)
+			next.ServeHTTP(w, r.WithContext(ContextWithTraceParent(r.Context(), tp)))
+		})
+	}
+}
+
+// ContextWithTraceParent returns a copy of ctx carrying the passed trace parent, also as trace_id and span_id log fields
+func ContextWithTraceParent(ctx context.Context, tp TraceParent) context.Context {
+	ctx = context.WithValue(ctx, traceParentCtxKey{}, tp)
+	return log.WithContext(ctx, log.FieldTraceID, tp.TraceID, log.FieldSpanID, tp.SpanID)
+}
+
+// TraceParentFromContext returns the trace parent carried by ctx, otherwise false
+func TraceParentFromContext(ctx context.Context) (TraceParent, bool) {
+	tp, ok := ctx.Value(traceParentCtxKey{}).(TraceParent)
+	return tp, ok
+}
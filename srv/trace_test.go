@@ -0,0 +1,63 @@
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/best4tires/kit/log"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		in      string
+		valid   bool
+		sampled bool
+	}{
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", valid: true, sampled: true},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", valid: true},
+		{in: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future", valid: true, sampled: true},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future"},
+		{in: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{in: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{in: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"},
+		{in: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			tp, err := ParseTraceParent(test.in)
+			testutil.AssertEqual(t, test.valid, err == nil)
+			testutil.AssertEqual(t, test.sampled, tp.Sampled())
+		})
+	}
+}
+
+func TestTraceContext(t *testing.T) {
+	parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	var have TraceParent
+	var fieldTraceID, fieldSpanID any
+	h := TraceContext()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		have, _ = TraceParentFromContext(r.Context())
+		fs := log.ContextFields(r.Context())
+		fieldTraceID, _ = fs.Value(log.FieldTraceID)
+		fieldSpanID, _ = fs.Value(log.FieldSpanID)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(HeaderTraceParent, parent)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	testutil.AssertEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", have.TraceID)
+	testutil.AssertEqual(t, true, have.SpanID != "00f067aa0ba902b7" && len(have.SpanID) == 16)
+	testutil.AssertEqual(t, true, have.Sampled())
+	testutil.AssertEqual(t, have.TraceID, fieldTraceID)
+	testutil.AssertEqual(t, have.SpanID, fieldSpanID)
+
+	// a new trace is started without a valid parent
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	_, err := ParseTraceParent(have.String())
+	testutil.AssertNoErr(t, err, "parse %q", have.String())
+	testutil.AssertEqual(t, true, have.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736")
+}
@@ -0,0 +1,236 @@
+package srv
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/best4tires/kit/convert"
+	"github.com/best4tires/kit/errs"
+)
+
+// Validator may be implemented by types bound by Bind or JSONHandler to validate a decoded request
+type Validator interface {
+	Validate() error
+}
+
+// Validate checks the rules declared in `validate` struct tags of v, a struct or a pointer to a struct,
+// including nested structs and slices of structs. Rules are separated by commas:
+//
+//	required     value must not be the zero value
+//	omitempty    other rules are not checked for the zero value
+//	min=N, max=N numbers must be >= N / <= N, strings, slices and maps must have at least / at most N elements
+//	len=N        strings, slices and maps must have exactly N elements
+//	oneof=a b c  value must be one of the space separated values
+//	email        value must be an email address
+//	regex=expr   string must match expr; it must be the last rule, since expr may contain commas
+//
+// Nil pointers are only checked by required. Violations are returned as field errors of errs.BadArgs,
+// fields are named by their json name.
+func Validate(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	e := errs.New(errs.CodeBadArgs, "")
+	if err := validateStruct(rv, "", &e); err != nil {
+		return err
+	}
+	if len(e.Fields()) > 0 {
+		return e
+	}
+	return nil
+}
+
+// validateStruct adds the violations of rv to e. It returns an error for invalid rules.
+func validateStruct(rv reflect.Value, prefix string, e **errs.Error) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := prefix + fieldName(sf)
+		fv := rv.Field(i)
+		if tag, ok := sf.Tag.Lookup("validate"); ok && tag != "-" {
+			if err := validateField(fv, name, tag, e); err != nil {
+				return fmt.Errorf("validate %s: %w", name, err)
+			}
+		}
+		if err := validateNested(fv, name, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateNested(fv reflect.Value, name string, e **errs.Error) error {
+	for fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+	switch fv.Kind() {
+	case reflect.Struct:
+		return validateStruct(fv, name+".", e)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < fv.Len(); i++ {
+			if err := validateNested(fv.Index(i), fmt.Sprintf("%s[%d]", name, i), e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fieldName returns the json name of the field
+func fieldName(sf reflect.StructField) string {
+	if tag, ok := sf.Tag.Lookup("json"); ok {
+		if n, _, _ := strings.Cut(tag, ","); n != "" && n != "-" {
+			return n
+		}
+	}
+	return sf.Name
+}
+
+func splitRules(tag string) []string {
+	var rules []string
+	for tag != "" {
+		if strings.HasPrefix(tag, "regex=") {
+			return append(rules, tag)
+		}
+		rule, rest, _ := strings.Cut(tag, ",")
+		rules = append(rules, strings.TrimSpace(rule))
+		tag = rest
+	}
+	return rules
+}
+
+func validateField(fv reflect.Value, name, tag string, e **errs.Error) error {
+	for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+		fv = fv.Elem()
+	}
+	zero := fv.IsZero()
+	rules := splitRules(tag)
+	for _, rule := range rules {
+		if rule == "omitempty" && zero {
+			rules = nil
+		}
+	}
+	for _, rule := range rules {
+		key, arg, _ := strings.Cut(rule, "=")
+		switch {
+		case key == "required":
+			if zero {
+				*e = (*e).WithField(name, "required")
+			}
+			continue
+		case key == "omitempty", fv.Kind() == reflect.Pointer:
+			continue
+		}
+		msg, err := checkRule(fv, key, arg)
+		if err != nil {
+			return err
+		}
+		if msg != "" {
+			*e = (*e).WithField(name, msg)
+		}
+	}
+	return nil
+}
+
+// checkRule returns a message, if fv violates the rule
+func checkRule(fv reflect.Value, key, arg string) (string, error) {
+	switch key {
+	case "min", "max", "len":
+		n, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return "", fmt.Errorf("rule %s: invalid argument %q", key, arg)
+		}
+		return checkBound(fv, key, n)
+	case "oneof":
+		s := convert.ToString(fv.Interface())
+		for _, o := range strings.Fields(arg) {
+			if s == o {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("must be one of [%s]", arg), nil
+	case "email":
+		s := convert.ToString(fv.Interface())
+		addr, err := mail.ParseAddress(s)
+		if err != nil || addr.Address != s {
+			return "must be a valid email address", nil
+		}
+		return "", nil
+	case "regex":
+		re, err := compileRegex(arg)
+		if err != nil {
+			return "", fmt.Errorf("rule regex: %w", err)
+		}
+		if !re.MatchString(convert.ToString(fv.Interface())) {
+			return fmt.Sprintf("must match %s", arg), nil
+		}
+		return "", nil
+	default:
+		return "", fmt.Errorf("unknown rule %q", key)
+	}
+}
+
+func checkBound(fv reflect.Value, key string, n float64) (string, error) {
+	var have float64
+	var what string
+	switch fv.Kind() {
+	case reflect.String:
+		have, what = float64(utf8.RuneCountInString(fv.String())), "length"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		have, what = float64(fv.Len()), "length"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		have, what = float64(fv.Int()), "value"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		have, what = float64(fv.Uint()), "value"
+	case reflect.Float32, reflect.Float64:
+		have, what = fv.Float(), "value"
+	default:
+		return "", fmt.Errorf("rule %s: unsupported type %s", key, fv.Type())
+	}
+	if key == "len" && what != "length" {
+		return "", fmt.Errorf("rule len: unsupported type %s", fv.Type())
+	}
+	arg := strconv.FormatFloat(n, 'g', -1, 64)
+	switch {
+	case key == "min" && have < n:
+		return fmt.Sprintf("%s must be at least %s", what, arg), nil
+	case key == "max" && have > n:
+		return fmt.Sprintf("%s must be at most %s", what, arg), nil
+	case key == "len" && have != n:
+		return fmt.Sprintf("%s must be %s", what, arg), nil
+	}
+	return "", nil
+}
+
+var regexCache sync.Map
+
+func compileRegex(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(expr, re)
+	return re, nil
+}
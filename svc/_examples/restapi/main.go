@@ -2,14 +2,11 @@ package main
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/best4tires/kit/env"
 	"github.com/best4tires/kit/errs"
-	"github.com/best4tires/kit/log"
 	"github.com/best4tires/kit/maps"
 	"github.com/best4tires/kit/srv"
 	"github.com/best4tires/kit/svc"
@@ -21,9 +18,9 @@ func main() {
 
 // Model
 type Foo struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
-	Bars int    `json:"bars"`
+	ID   string `json:"id" validate:"required,len=3"`
+	Name string `json:"name" validate:"required,max=64"`
+	Bars int    `json:"bars" validate:"min=0"`
 }
 
 type Repository struct {
@@ -82,9 +79,15 @@ func NewService(fooCount int) *Service {
 }
 
 func (s *Service) Route(router *srv.PrefixRouter) {
-	router.GET("foos/", s.handleGETFoos)
-	router.POST("foos/", s.handlePOSTFoos)
-	router.GET("foos/{id}", s.handleGETFoo)
+	router.GET("foos/", srv.JSONHandler(s.handleGETFoos),
+		srv.Summary("list all foos"), srv.Tags{"foos"},
+		srv.ResponseType{Status: http.StatusOK, Value: []Foo{}})
+	router.POST("foos/", srv.JSONHandler(s.handlePOSTFoos),
+		srv.Summary("insert or update a foo"), srv.Tags{"foos"},
+		srv.RequestType{Value: Foo{}}, srv.ResponseType{Status: http.StatusOK, Value: []Foo{}})
+	router.GET("foos/{id}", srv.JSONHandler(s.handleGETFoo),
+		srv.Summary("get a foo"), srv.Tags{"foos"},
+		srv.RequestType{Value: fooRequest{}}, srv.ResponseType{Status: http.StatusOK, Value: Foo{}})
 }
 
 func (s *Service) RunCtx(ctx context.Context, env env.Env) error {
@@ -96,41 +99,23 @@ func (s *Service) Shutdown() {
 }
 
 // handler
-func (s *Service) handleGETFoos(w http.ResponseWriter, r *http.Request) {
-	foos, err := s.repo.FindAll()
-	if err != nil {
-		http.Error(w, "internal error", http.StatusInternalServerError)
-		return
-	}
-	srv.WriteJSON(w, http.StatusOK, foos)
+func (s *Service) handleGETFoos(ctx context.Context, _ struct{}) ([]Foo, error) {
+	return s.repo.FindAll()
 }
 
-func (s *Service) handlePOSTFoos(w http.ResponseWriter, r *http.Request) {
-	var foo Foo
-	err := json.NewDecoder(r.Body).Decode(&foo)
-	if err != nil {
-		http.Error(w, "json decode error", http.StatusBadRequest)
-		return
-	}
+func (s *Service) handlePOSTFoos(ctx context.Context, foo Foo) ([]Foo, error) {
 	s.repo.InsertOrUpdate(foo)
-	foos, err := s.repo.FindAll()
+	return s.repo.FindAll()
+}
+
+type fooRequest struct {
+	ID string `path:"id"`
+}
+
+func (s *Service) handleGETFoo(ctx context.Context, req fooRequest) (Foo, error) {
+	v, err := s.repo.Find(req.ID)
 	if err != nil {
-		http.Error(w, "internal error", http.StatusInternalServerError)
-		return
-	}
-	srv.WriteJSON(w, http.StatusOK, foos)
-}
-
-func (s *Service) handleGETFoo(w http.ResponseWriter, r *http.Request) {
-	id := srv.Var(r, "id")
-	v, err := s.repo.Find(id)
-	switch {
-	case err == nil:
-		srv.WriteJSON(w, http.StatusOK, v)
-	case errors.Is(err, errs.NotFound()):
-		http.Error(w, fmt.Sprintf("no such foo %q", id), http.StatusNotFound)
-	default:
-		log.Errorf("find %q: %v", id, err)
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		return Foo{}, fmt.Errorf("find foo %q: %w", req.ID, err)
 	}
+	return v, nil
 }
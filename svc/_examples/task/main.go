@@ -5,49 +5,46 @@ import (
 	"math/rand"
 	"time"
 
-	"github.com/best4tires/kit/env"
 	"github.com/best4tires/kit/log"
-	"github.com/best4tires/kit/srv"
 	"github.com/best4tires/kit/svc"
 )
 
 func main() {
-	svc.NewRuntimeEnvironment("bartask").Run(NewService())
+	svc.NewRuntimeEnvironment("bartask").Run(NewScheduler())
 }
 
-// Service definition
-type Service struct {
+func NewScheduler() *svc.Scheduler {
+	return svc.NewScheduler("bartask",
+		svc.Job{
+			Name:       "execute",
+			Schedule:   svc.Every(2 * time.Second),
+			Jitter:     500 * time.Millisecond,
+			Timeout:    time.Minute,
+			RunAtStart: true,
+			Run:        execute,
+		},
+		svc.Job{
+			Name:     "report",
+			Schedule: svc.MustParseCron("*/5 * * * *"),
+			Run:      report,
+		},
+	)
 }
 
-func NewService() *Service {
-	return &Service{}
-}
-
-func (s *Service) Route(router *srv.PrefixRouter) {}
-
-func (s *Service) Shutdown() {}
-
-func (s *Service) RunCtx(ctx context.Context, env env.Env) error {
-	timer := time.NewTimer(0)
-	for {
-		select {
-		case <-ctx.Done():
-			log.Infof("context is done ... exit loop")
-			return nil
-		case <-timer.C:
-			s.executeCtx(ctx)
-			timer.Reset(2 * time.Second)
-		}
-	}
-	return nil
-}
-
-func (s *Service) executeCtx(ctx context.Context) {
+func execute(ctx context.Context) error {
 	dur := time.Duration(1000+rand.Intn(2000)) * time.Millisecond
-	log.Infof("executing for %s ...", dur)
+	logger := log.FromContext(ctx).With("duration", dur)
+	logger.Infof("executing ...")
 	select {
 	case <-time.After(dur):
 	case <-ctx.Done():
+		return ctx.Err()
 	}
-	log.Infof("executing for %s ... done", dur)
+	logger.Infof("executing ... done")
+	return nil
+}
+
+func report(ctx context.Context) error {
+	log.FromContext(ctx).Infof("reporting")
+	return nil
 }
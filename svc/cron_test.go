@@ -0,0 +1,42 @@
+package svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2023, 4, 5, 10, 17, 30, 0, time.UTC) // wednesday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{expr: "* * * * *", want: time.Date(2023, 4, 5, 10, 18, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", want: time.Date(2023, 4, 5, 10, 30, 0, 0, time.UTC)},
+		{expr: "0 9-17/4 * * *", want: time.Date(2023, 4, 5, 13, 0, 0, 0, time.UTC)},
+		{expr: "5,10 3 * * *", want: time.Date(2023, 4, 6, 3, 5, 0, 0, time.UTC)},
+		{expr: "0 0 1 * *", want: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "30 8 * * mon-fri", want: time.Date(2023, 4, 6, 8, 30, 0, 0, time.UTC)},
+		{expr: "0 12 * * 7", want: time.Date(2023, 4, 9, 12, 0, 0, 0, time.UTC)},
+		{expr: "0 0 13 * fri", want: time.Date(2023, 4, 7, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 feb *", want: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "@daily", want: time.Date(2023, 4, 6, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 31 2 *", want: time.Time{}},
+	}
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			cs, err := ParseCron(test.expr)
+			testutil.AssertNoErr(t, err, "parse")
+			testutil.AssertEqual(t, test.want, cs.Next(from))
+		})
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "x * * * *"} {
+		_, err := ParseCron(expr)
+		testutil.AssertEqual(t, true, err != nil)
+	}
+}
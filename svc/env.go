@@ -2,20 +2,35 @@ package svc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/best4tires/kit/convert"
 	"github.com/best4tires/kit/env"
 	"github.com/best4tires/kit/log"
+	"github.com/best4tires/kit/log/entry"
 	"github.com/best4tires/kit/srv"
 )
 
 const (
-	envKeyHttpPort   = "http.port"
-	envKeyHttpPrefix = "http.prefix"
+	envKeyHttpPort        = "http.port"
+	envKeyHttpPrefix      = "http.prefix"
+	envKeyLogLevel        = "log.level"
+	envKeyLogLevelHandler = "log.level.handler"
+	envKeyVersion         = "version"
+
+	envKeyShutdownDrainDelay    = "shutdown.drain.delay"
+	envKeyShutdownServerTimeout = "shutdown.server.timeout"
+	envKeyShutdownWaitTimeout   = "shutdown.wait.timeout"
+
+	// defaultDrainDelay gives load balancers time to notice the failing readiness before the server closes connections
+	defaultDrainDelay = 5 * time.Second
 )
 
 type Service interface {
@@ -47,21 +62,56 @@ func (e *RuntimeEnvironment) run(svcs ...Service) (err error) {
 	defer func() {
 		if perr := recover(); perr != nil {
 			err = fmt.Errorf("runtime-env: recovered: %v", perr)
-			log.Errorf("%v", err)
-			log.DebugStack()
+			log.Err(err).WithStack().Errorf("%v", err)
 		}
 	}()
 	env := env.Load()
 
+	//log level
+	if ls, ok := env.StringWithTag(envKeyLogLevel, e.name); ok {
+		l, err := entry.ParseLevel(ls)
+		if err != nil {
+			return fmt.Errorf("parse %q: %w", envKeyLogLevel, err)
+		}
+		log.SetLevel(l)
+	}
+
 	//http params
 	httpPort := env.StringWithTagOrDefault(envKeyHttpPort, e.name, "0")
 	httpPrefix := env.StringWithTagOrDefault(envKeyHttpPrefix, e.name, fmt.Sprintf("/api/%s/", e.name))
 
 	//router
-	router := srv.NewRouter().WithPrefix(httpPrefix)
+	rootRouter := srv.NewRouter()
+	rootRouter.GET("/metrics", srv.MetricsHandler().ServeHTTP, srv.Undocumented{})
+	health := newHealth(svcs...)
+	health.route(rootRouter)
+	router := rootRouter.WithPrefix(httpPrefix)
 	for _, svc := range svcs {
 		svc.Route(router)
 	}
+	router.GET("openapi.json", rootRouter.OpenAPIHandler(srv.OpenAPIInfo{
+		Title:   e.name,
+		Version: env.StringWithTagOrDefault(envKeyVersion, e.name, "unversioned"),
+	}), srv.Undocumented{})
+	if v, ok := env.StringWithTag(envKeyLogLevelHandler, e.name); ok && convert.ToBool(v) {
+		lh := log.LevelHandler()
+		router.GET("log/level", lh.ServeHTTP, srv.Undocumented{})
+		router.PUT("log/level", lh.ServeHTTP, srv.Undocumented{})
+	}
+
+	//shutdown params
+	drainDelay, err := durationVar(env, envKeyShutdownDrainDelay, e.name, defaultDrainDelay)
+	if err != nil {
+		return err
+	}
+	serverTimeout, err := durationVar(env, envKeyShutdownServerTimeout, e.name, 3*time.Second)
+	if err != nil {
+		return err
+	}
+	waitTimeout, err := durationVar(env, envKeyShutdownWaitTimeout, e.name, 5*time.Second)
+	if err != nil {
+		return err
+	}
 
 	//server
 	bind := fmt.Sprintf(":%s", httpPort)
@@ -70,33 +120,64 @@ func (e *RuntimeEnvironment) run(svcs ...Service) (err error) {
 		return fmt.Errorf("new-server on %q: %w", bind, err)
 	}
 	handler := router.Handler(
+		srv.RequestID(),
+		srv.TraceContext(),
+		srv.Metrics(),
 		srv.GZIP(),
 		srv.Recovery(),
 		srv.Logging(false),
 	)
+
+	// the shared context is cancelled by a signal or by the first failing service
+	ctx, cancel := context.WithCancel(log.WithContext(context.Background(), "service", e.name))
+	defer cancel()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errs := &runErrors{}
+	fail := func(err error) {
+		errLogger(log.FromContext(ctx), err).Errorf("%v", err)
+		errs.add(err)
+		cancel()
+	}
+
 	log.Infof("listen to %q", server.Addr().String())
-	go server.Run(handler)
+	serverDoneC := make(chan struct{})
+	go func() {
+		defer close(serverDoneC)
+		if err := server.Run(handler); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fail(fmt.Errorf("run-server: %w", err))
+		}
+	}()
 
 	// run services
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
-	defer cancel()
 	wg := sync.WaitGroup{}
 	for _, svc := range svcs {
 		wg.Add(1)
 		go func(s Service) {
 			defer wg.Done()
-			s.RunCtx(ctx, env)
+			if err := supervise(ctx, s, env); err != nil {
+				fail(err)
+			}
 		}(svc)
 	}
 
 	// wait until done
 	<-ctx.Done()
+	log.Infof("shutdown started")
+	health.shutdown()
+	// drain only on a signal; after a failure, there is no point in keeping the server up
+	if drainDelay > 0 && errs.err() == nil {
+		time.Sleep(drainDelay)
+	}
 
 	// shutdown server and wait for services in parallel
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		server.Shutdown(3 * time.Second)
+		if err := server.Shutdown(serverTimeout); err != nil {
+			errs.add(fmt.Errorf("shutdown-server: %w", err))
+		}
+		<-serverDoneC
 	}()
 
 	waitC := make(chan struct{})
@@ -106,12 +187,41 @@ func (e *RuntimeEnvironment) run(svcs ...Service) (err error) {
 	}()
 	select {
 	case <-waitC:
-	case <-time.After(5 * time.Second):
+	case <-time.After(waitTimeout):
+		log.Warnf("shutdown: services did not finish within %s", waitTimeout)
 	}
 
-	for _, svc := range svcs {
-		svc.Shutdown()
+	shutdownInPhases(svcs)
+
+	return errs.err()
+}
+
+func durationVar(env env.Env, key string, tag string, def time.Duration) (time.Duration, error) {
+	s, ok := env.StringWithTag(key, tag)
+	if !ok {
+		return def, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("parse %q: %w", key, err)
 	}
+	return d, nil
+}
+
+// runErrors collects the errors of the server and all services
+type runErrors struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (re *runErrors) add(err error) {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+	re.errs = append(re.errs, err)
+}
 
-	return nil
+func (re *runErrors) err() error {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+	return errors.Join(re.errs...)
 }
@@ -0,0 +1,133 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/best4tires/kit/env"
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/log/logtest"
+	"github.com/best4tires/kit/srv"
+	"github.com/best4tires/kit/testutil"
+)
+
+type testService struct {
+	name    string
+	phase   int
+	run     func(ctx context.Context) error
+	mu      *sync.Mutex
+	shutdns *[]string
+}
+
+func (s *testService) Route(router *srv.PrefixRouter) {}
+
+func (s *testService) RunCtx(ctx context.Context, env env.Env) error {
+	return s.run(ctx)
+}
+
+func (s *testService) Shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	*s.shutdns = append(*s.shutdns, s.name)
+}
+
+func (s *testService) ShutdownPhase() int {
+	return s.phase
+}
+
+// noDrain disables the drain delay of the runtime environment
+func noDrain(t *testing.T) {
+	t.Setenv(envKeyShutdownDrainDelay, "0s")
+}
+
+func TestRunServiceError(t *testing.T) {
+	logtest.Install(t)
+
+	var mu sync.Mutex
+	var shutdns []string
+	errBoom := errors.New("boom")
+	failing := &testService{name: "failing", phase: 2, mu: &mu, shutdns: &shutdns, run: func(ctx context.Context) error {
+		return errBoom
+	}}
+	waiting := &testService{name: "waiting", phase: 1, mu: &mu, shutdns: &shutdns, run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	t0 := time.Now()
+	err := NewRuntimeEnvironment("test").run(failing, waiting)
+	testutil.AssertEqual(t, true, errors.Is(err, errBoom))
+	// a failure skips the drain delay
+	testutil.AssertEqual(t, true, time.Since(t0) < defaultDrainDelay)
+	testutil.AssertEqual(t, []string{"waiting", "failing"}, shutdns)
+}
+
+func TestRunServicePanic(t *testing.T) {
+	rec := logtest.Install(t)
+	noDrain(t)
+
+	var mu sync.Mutex
+	var shutdns []string
+	panicking := &testService{name: "panicking", mu: &mu, shutdns: &shutdns, run: func(ctx context.Context) error {
+		panic("boom")
+	}}
+
+	err := NewRuntimeEnvironment("test").run(panicking)
+	testutil.AssertEqual(t, "run *svc.testService: recovered: boom", err.Error())
+	rec.AssertLogged(entry.LevelError, "recovered: boom")
+	testutil.AssertEqual(t, []string{"panicking"}, shutdns)
+}
+
+func TestRunDrain(t *testing.T) {
+	logtest.Install(t)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	testutil.AssertNoErr(t, err, "listen")
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	t.Setenv(envKeyHttpPort, strconv.Itoa(port))
+	t.Setenv(envKeyShutdownDrainDelay, "500ms")
+
+	var mu sync.Mutex
+	var shutdns []string
+	waiting := &testService{name: "waiting", mu: &mu, shutdns: &shutdns, run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}}
+	doneC := make(chan error)
+	go func() {
+		doneC <- NewRuntimeEnvironment("test").run(waiting)
+	}()
+
+	readyz := fmt.Sprintf("http://127.0.0.1:%d/readyz", port)
+	// no idle connections, which would delay the server shutdown
+	clt := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	waitStatus := func(status int) {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			if resp, err := clt.Get(readyz); err == nil {
+				resp.Body.Close()
+				if resp.StatusCode == status {
+					return
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("readyz: status %d not reached", status)
+	}
+	waitStatus(http.StatusOK)
+	testutil.AssertNoErr(t, syscall.Kill(os.Getpid(), syscall.SIGTERM), "kill")
+	// the server still serves, but reports not being ready
+	waitStatus(http.StatusServiceUnavailable)
+
+	testutil.AssertNoErr(t, <-doneC, "run")
+	testutil.AssertEqual(t, []string{"waiting"}, shutdns)
+}
@@ -0,0 +1,164 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/best4tires/kit/srv"
+)
+
+// DefaultHealthCheckTimeout is used for health checks without an explicit timeout
+const DefaultHealthCheckTimeout = 2 * time.Second
+
+// HealthCheck is a single named check. Liveness checks are reported by /healthz and /readyz,
+// all other checks only by /readyz.
+type HealthCheck struct {
+	Name     string
+	Check    func(ctx context.Context) error
+	Timeout  time.Duration
+	Liveness bool
+}
+
+// HealthChecker may be implemented by a Service to contribute checks to the health endpoints
+type HealthChecker interface {
+	HealthChecks() []HealthCheck
+}
+
+const (
+	healthStatusOK      = "ok"
+	healthStatusFailing = "failing"
+)
+
+type HealthCheckResult struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+type HealthReport struct {
+	Status string              `json:"status"`
+	Checks []HealthCheckResult `json:"checks"`
+}
+
+type health struct {
+	checks       []HealthCheck
+	shuttingDown atomic.Bool
+}
+
+func newHealth(svcs ...Service) *health {
+	h := &health{}
+	for _, svc := range svcs {
+		if hc, ok := svc.(HealthChecker); ok {
+			h.checks = append(h.checks, hc.HealthChecks()...)
+		}
+	}
+	return h
+}
+
+// shutdown marks the environment as shutting down, so that readiness fails from now on
+func (h *health) shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+func (h *health) route(router *srv.Router) {
+	router.GET("/healthz", h.handleLiveness, srv.Undocumented{})
+	router.GET("/readyz", h.handleReadiness, srv.Undocumented{})
+}
+
+func (h *health) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	var checks []HealthCheck
+	for _, c := range h.checks {
+		if c.Liveness {
+			checks = append(checks, c)
+		}
+	}
+	h.write(w, h.run(r.Context(), checks))
+}
+
+func (h *health) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	report := h.run(r.Context(), h.checks)
+	if h.shuttingDown.Load() {
+		report.Status = healthStatusFailing
+		report.Checks = append(report.Checks, HealthCheckResult{
+			Name:     "shutdown",
+			Status:   healthStatusFailing,
+			Error:    "shutdown in progress",
+			Duration: "0s",
+		})
+	}
+	h.write(w, report)
+}
+
+func (h *health) write(w http.ResponseWriter, report HealthReport) {
+	status := http.StatusOK
+	if report.Status != healthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	srv.WriteJSON(w, status, report)
+}
+
+// run executes the checks in parallel, each bounded by its timeout
+func (h *health) run(ctx context.Context, checks []HealthCheck) HealthReport {
+	report := HealthReport{
+		Status: healthStatusOK,
+		Checks: make([]HealthCheckResult, len(checks)),
+	}
+	wg := sync.WaitGroup{}
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c HealthCheck) {
+			defer wg.Done()
+			report.Checks[i] = runHealthCheck(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+	for _, res := range report.Checks {
+		if res.Status != healthStatusOK {
+			report.Status = healthStatusFailing
+		}
+	}
+	return report
+}
+
+func runHealthCheck(ctx context.Context, c HealthCheck) HealthCheckResult {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	t0 := time.Now()
+	errC := make(chan error, 1)
+	go func() {
+		defer func() {
+			if perr := recover(); perr != nil {
+				errC <- fmt.Errorf("recovered: %v", perr)
+			}
+		}()
+		errC <- c.Check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-errC:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+
+	res := HealthCheckResult{
+		Name:     c.Name,
+		Status:   healthStatusOK,
+		Duration: time.Since(t0).String(),
+	}
+	if err != nil {
+		res.Status = healthStatusFailing
+		res.Error = err.Error()
+	}
+	return res
+}
@@ -0,0 +1,87 @@
+package svc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/best4tires/kit/env"
+	"github.com/best4tires/kit/srv"
+	"github.com/best4tires/kit/testutil"
+)
+
+type checkedService struct {
+	checks []HealthCheck
+}
+
+func (s *checkedService) Route(router *srv.PrefixRouter)                {}
+func (s *checkedService) RunCtx(ctx context.Context, env env.Env) error { return nil }
+func (s *checkedService) Shutdown()                                     {}
+func (s *checkedService) HealthChecks() []HealthCheck                   { return s.checks }
+
+func probe(t *testing.T, h http.Handler, path string) (int, HealthReport) {
+	t.Helper()
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+	var report HealthReport
+	testutil.AssertNoErr(t, json.NewDecoder(resp.Body).Decode(&report), "decode %s", path)
+	return resp.Code, report
+}
+
+func TestHealth(t *testing.T) {
+	dbErr := errors.New("db unreachable")
+	var dbFails bool
+	s := &checkedService{checks: []HealthCheck{
+		{Name: "loop", Liveness: true, Check: func(ctx context.Context) error { return nil }},
+		{Name: "db", Check: func(ctx context.Context) error {
+			if dbFails {
+				return dbErr
+			}
+			return nil
+		}},
+		{Name: "slow", Timeout: 10 * time.Millisecond, Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}},
+	}}
+	h := newHealth(s)
+	router := srv.NewRouter()
+	h.route(router)
+	handler := router.Handler()
+	testutil.AssertEqual(t, 0, len(router.Routes()))
+
+	code, report := probe(t, handler, "/healthz")
+	testutil.AssertEqual(t, http.StatusOK, code)
+	testutil.AssertEqual(t, 1, len(report.Checks))
+	testutil.AssertEqual(t, "loop", report.Checks[0].Name)
+
+	code, report = probe(t, handler, "/readyz")
+	testutil.AssertEqual(t, http.StatusServiceUnavailable, code)
+	testutil.AssertEqual(t, healthStatusFailing, report.Status)
+	testutil.AssertEqual(t, "timed out after 10ms", report.Checks[2].Error)
+
+	s.checks = s.checks[:2]
+	h = newHealth(s)
+	router = srv.NewRouter()
+	h.route(router)
+	handler = router.Handler()
+	code, _ = probe(t, handler, "/readyz")
+	testutil.AssertEqual(t, http.StatusOK, code)
+
+	dbFails = true
+	code, report = probe(t, handler, "/readyz")
+	testutil.AssertEqual(t, http.StatusServiceUnavailable, code)
+	testutil.AssertEqual(t, dbErr.Error(), report.Checks[1].Error)
+
+	dbFails = false
+	h.shutdown()
+	code, report = probe(t, handler, "/readyz")
+	testutil.AssertEqual(t, http.StatusServiceUnavailable, code)
+	testutil.AssertEqual(t, "shutdown", report.Checks[2].Name)
+	code, _ = probe(t, handler, "/healthz")
+	testutil.AssertEqual(t, http.StatusOK, code)
+}
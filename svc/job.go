@@ -0,0 +1,154 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/best4tires/kit/env"
+	"github.com/best4tires/kit/log"
+	"github.com/best4tires/kit/srv"
+)
+
+// Job is a function run by a Scheduler according to its schedule.
+// Runs are delayed by a random jitter in [0, Jitter) and cancelled after Timeout (if > 0).
+// A run is skipped, if the previous run is still in progress, unless AllowOverlap is set.
+type Job struct {
+	Name         string
+	Schedule     Schedule
+	Run          func(ctx context.Context) error
+	Jitter       time.Duration
+	Timeout      time.Duration
+	AllowOverlap bool
+	// RunAtStart runs the job once when the scheduler starts, in addition to its schedule
+	RunAtStart bool
+}
+
+// Scheduler runs jobs on their schedules. It implements Service.
+type Scheduler struct {
+	name string
+	jobs []Job
+}
+
+// NewScheduler creates a scheduler for the given jobs
+func NewScheduler(name string, jobs ...Job) *Scheduler {
+	return &Scheduler{
+		name: name,
+		jobs: jobs,
+	}
+}
+
+// Add adds jobs to the scheduler. It must be called before the scheduler runs.
+func (s *Scheduler) Add(jobs ...Job) {
+	s.jobs = append(s.jobs, jobs...)
+}
+
+func (s *Scheduler) Name() string {
+	return s.name
+}
+
+func (s *Scheduler) Route(router *srv.PrefixRouter) {}
+
+func (s *Scheduler) Shutdown() {}
+
+// RunCtx runs all jobs until ctx is done and waits for running jobs to return
+func (s *Scheduler) RunCtx(ctx context.Context, env env.Env) error {
+	for _, job := range s.jobs {
+		if job.Schedule == nil || job.Run == nil {
+			return fmt.Errorf("job %q: schedule and run are required", job.Name)
+		}
+		if d, ok := job.Schedule.(interval); ok && d <= 0 {
+			return fmt.Errorf("job %q: interval must be positive, got %s", job.Name, time.Duration(d))
+		}
+	}
+	wg := sync.WaitGroup{}
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(log.WithContext(ctx, "job", job.Name), job)
+		}(job)
+	}
+	wg.Wait()
+	return nil
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	logger := log.FromContext(ctx)
+	runs := sync.WaitGroup{}
+	defer runs.Wait()
+	var running atomic.Bool
+	trigger := func() {
+		if !job.AllowOverlap && !running.CompareAndSwap(false, true) {
+			logger.Warnf("job %q: skipped, previous run still in progress", job.Name)
+			return
+		}
+		runs.Add(1)
+		go func() {
+			defer runs.Done()
+			if !job.AllowOverlap {
+				defer running.Store(false)
+			}
+			s.run(ctx, job)
+		}()
+	}
+
+	if job.RunAtStart {
+		trigger()
+	}
+	next := job.Schedule.Next(time.Now())
+	for {
+		if next.IsZero() {
+			logger.Warnf("job %q: schedule has no further activation", job.Name)
+			<-ctx.Done()
+			return
+		}
+		delay := next.Sub(time.Now())
+		if job.Jitter > 0 {
+			delay += time.Duration(rand.Int63n(int64(job.Jitter)))
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		trigger()
+		// schedule from the planned activation to avoid drift, but don't catch up on missed activations
+		now := time.Now()
+		next = job.Schedule.Next(next)
+		if !next.IsZero() && next.Before(now) {
+			next = job.Schedule.Next(now)
+		}
+	}
+}
+
+// run executes a single run of the job, recovering from panics
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+	logger := log.FromContext(ctx)
+	t0 := time.Now()
+	defer func() {
+		if perr := recover(); perr != nil {
+			err := fmt.Errorf("recovered: %v", perr)
+			logger.Err(err).WithStack().Errorf("job %q: %v", job.Name, err)
+		}
+	}()
+	logger.Debugf("job %q: run", job.Name)
+	if err := job.Run(ctx); err != nil {
+		logger.Err(err).Errorf("job %q: failed after %s: %v", job.Name, time.Since(t0), err)
+		return
+	}
+	logger.Debugf("job %q: done in %s", job.Name, time.Since(t0))
+}
@@ -0,0 +1,78 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/best4tires/kit/env"
+	"github.com/best4tires/kit/log"
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/log/logtest"
+	"github.com/best4tires/kit/testutil"
+)
+
+func TestScheduler(t *testing.T) {
+	rec := logtest.Install(t)
+
+	var fast, slow, timedOut atomic.Int32
+	s := NewScheduler("jobs",
+		Job{Name: "fast", Schedule: Every(10 * time.Millisecond), RunAtStart: true, Run: func(ctx context.Context) error {
+			fast.Add(1)
+			log.FromContext(ctx).Infof("fast run")
+			return nil
+		}},
+		Job{Name: "slow", Schedule: Every(10 * time.Millisecond), Run: func(ctx context.Context) error {
+			slow.Add(1)
+			<-ctx.Done()
+			return nil
+		}},
+		Job{Name: "timeout", Schedule: Every(time.Hour), RunAtStart: true, Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			timedOut.Add(1)
+			return ctx.Err()
+		}},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	testutil.AssertNoErr(t, s.RunCtx(ctx, env.Env{}), "run")
+
+	testutil.AssertEqual(t, true, fast.Load() >= 5)
+	testutil.AssertEqual(t, int32(1), slow.Load())
+	testutil.AssertEqual(t, int32(1), timedOut.Load())
+	rec.AssertLogged(entry.LevelWarn, `job "slow": skipped`)
+	rec.AssertLogged(entry.LevelError, `job "timeout": failed`)
+
+	e, ok := rec.Find(func(e entry.Entry) bool { return e.Message == "fast run" })
+	testutil.AssertEqual(t, true, ok)
+	name, _ := e.Fields.Value("job")
+	testutil.AssertEqual(t, "fast", name)
+}
+
+func TestSchedulerPanic(t *testing.T) {
+	rec := logtest.Install(t)
+
+	s := NewScheduler("jobs", Job{Name: "panics", Schedule: Every(time.Hour), RunAtStart: true, Run: func(ctx context.Context) error {
+		panic(errors.New("boom"))
+	}})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	testutil.AssertNoErr(t, s.RunCtx(ctx, env.Env{}), "run")
+	rec.AssertLogged(entry.LevelError, `job "panics": recovered: boom`)
+}
+
+func TestSchedulerInvalidInterval(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		s := NewScheduler("jobs", Job{Name: "spin", Schedule: Every(d), Run: func(ctx context.Context) error {
+			t.Fatalf("job must not run")
+			return nil
+		}})
+		err := s.RunCtx(context.Background(), env.Env{})
+		if err == nil {
+			t.Fatalf("%s: want error", d)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package svc
+
+import (
+	"sort"
+	"sync"
+)
+
+// ShutdownPhaser may be implemented by a Service to take part in an ordered shutdown.
+// Services are shut down in ascending phase order, services of the same phase concurrently.
+// Services not implementing ShutdownPhaser are in phase 0.
+type ShutdownPhaser interface {
+	ShutdownPhase() int
+}
+
+func shutdownPhase(s Service) int {
+	if sp, ok := s.(ShutdownPhaser); ok {
+		return sp.ShutdownPhase()
+	}
+	return 0
+}
+
+func shutdownInPhases(svcs []Service) {
+	phases := map[int][]Service{}
+	for _, s := range svcs {
+		p := shutdownPhase(s)
+		phases[p] = append(phases[p], s)
+	}
+	order := make([]int, 0, len(phases))
+	for p := range phases {
+		order = append(order, p)
+	}
+	sort.Ints(order)
+	for _, p := range order {
+		wg := sync.WaitGroup{}
+		for _, s := range phases[p] {
+			wg.Add(1)
+			go func(s Service) {
+				defer wg.Done()
+				s.Shutdown()
+			}(s)
+		}
+		wg.Wait()
+	}
+}
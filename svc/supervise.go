@@ -0,0 +1,174 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/best4tires/kit/env"
+	"github.com/best4tires/kit/log"
+)
+
+// RestartMode defines, when a service is restarted after RunCtx returned
+type RestartMode int
+
+const (
+	// RestartNever never restarts a service
+	RestartNever RestartMode = iota
+	// RestartOnFailure restarts a service, if RunCtx returned an error or panicked
+	RestartOnFailure
+	// RestartAlways restarts a service whenever RunCtx returns before shutdown
+	RestartAlways
+)
+
+// ExhaustedAction defines, what happens when a service failed and may not be restarted (anymore)
+type ExhaustedAction int
+
+const (
+	// FailProcess cancels all services and makes the process exit with an error
+	FailProcess ExhaustedAction = iota
+	// KeepGoing logs the failure and keeps the other services running
+	KeepGoing
+)
+
+// RestartPolicy controls the supervision of a service.
+// Restarts are delayed by an exponential backoff starting at Backoff and capped at MaxBackoff.
+// MaxRestarts limits the number of consecutive restarts (0 = unlimited); a run lasting longer than MaxBackoff
+// resets the count and the backoff.
+type RestartPolicy struct {
+	Mode        RestartMode
+	MaxRestarts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	OnExhausted ExhaustedAction
+}
+
+// DefaultRestartPolicy is used for services not implementing Supervised
+var DefaultRestartPolicy = RestartPolicy{
+	Mode:        RestartNever,
+	Backoff:     time.Second,
+	MaxBackoff:  time.Minute,
+	OnExhausted: FailProcess,
+}
+
+// Supervised may be implemented by a Service to define its restart policy
+type Supervised interface {
+	RestartPolicy() RestartPolicy
+}
+
+// Namer may be implemented by a Service to provide a name for logs and errors. Otherwise the type name is used.
+type Namer interface {
+	Name() string
+}
+
+func serviceName(s Service) string {
+	if n, ok := s.(Namer); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", s)
+}
+
+func restartPolicy(s Service) RestartPolicy {
+	p := DefaultRestartPolicy
+	if sp, ok := s.(Supervised); ok {
+		p = sp.RestartPolicy()
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = DefaultRestartPolicy.Backoff
+	}
+	if p.MaxBackoff < p.Backoff {
+		p.MaxBackoff = p.Backoff
+	}
+	return p
+}
+
+// panicError is a recovered panic of a service
+type panicError struct {
+	value any
+	stack string
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("recovered: %v", e.value)
+}
+
+// runSafe runs the service and turns a panic into an error
+func runSafe(ctx context.Context, s Service, env env.Env) (err error) {
+	defer func() {
+		if perr := recover(); perr != nil {
+			err = &panicError{value: perr, stack: log.Stack(1)}
+		}
+	}()
+	return s.RunCtx(ctx, env)
+}
+
+// errLogger returns a child of logger for err, which carries the stack of a recovered panic
+func errLogger(logger *log.Logger, err error) *log.Logger {
+	logger = logger.Err(err)
+	var pe *panicError
+	if errors.As(err, &pe) {
+		logger = logger.With(log.FieldStack, pe.stack)
+	}
+	return logger
+}
+
+// supervise runs the service according to its restart policy until ctx is done.
+// It returns an error, if the service failed and the policy demands to fail the process.
+func supervise(ctx context.Context, s Service, env env.Env) error {
+	name := serviceName(s)
+	policy := restartPolicy(s)
+	// service_name identifies the supervised service, whereas service names the runtime environment
+	ctx = log.WithContext(ctx, "service_name", name)
+	logger := log.FromContext(ctx)
+
+	restarts := 0
+	backoff := policy.Backoff
+	for {
+		t0 := time.Now()
+		err := runSafe(ctx, s, env)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if time.Since(t0) > policy.MaxBackoff {
+			restarts = 0
+			backoff = policy.Backoff
+		}
+
+		restart := policy.Mode == RestartAlways || (policy.Mode == RestartOnFailure && err != nil)
+		if restart && policy.MaxRestarts > 0 && restarts >= policy.MaxRestarts {
+			restart = false
+			if err == nil {
+				err = fmt.Errorf("stopped")
+			}
+			err = fmt.Errorf("gave up after %d restarts: %w", restarts, err)
+		}
+		if !restart {
+			if err == nil {
+				logger.Infof("%s: finished", name)
+				return nil
+			}
+			if policy.OnExhausted == KeepGoing {
+				errLogger(logger, err).Errorf("%s: failed, keep going: %v", name, err)
+				return nil
+			}
+			return fmt.Errorf("run %s: %w", name, err)
+		}
+
+		restarts++
+		if err != nil {
+			errLogger(logger, err).Warnf("%s: failed, restart #%d in %s: %v", name, restarts, backoff, err)
+		} else {
+			logger.Warnf("%s: returned early, restart #%d in %s", name, restarts, backoff)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/best4tires/kit/env"
+	"github.com/best4tires/kit/log"
+	"github.com/best4tires/kit/log/entry"
+	"github.com/best4tires/kit/log/logtest"
+	"github.com/best4tires/kit/srv"
+	"github.com/best4tires/kit/testutil"
+)
+
+type flakyService struct {
+	policy RestartPolicy
+	fails  int
+	runs   int
+}
+
+func (s *flakyService) Name() string                   { return "flaky" }
+func (s *flakyService) Route(router *srv.PrefixRouter) {}
+func (s *flakyService) Shutdown()                      {}
+func (s *flakyService) RestartPolicy() RestartPolicy   { return s.policy }
+
+func (s *flakyService) RunCtx(ctx context.Context, env env.Env) error {
+	s.runs++
+	if s.runs <= s.fails {
+		if s.runs%2 == 0 {
+			panic("broker gone")
+		}
+		return errors.New("broker unavailable")
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func TestSupervise(t *testing.T) {
+	policy := RestartPolicy{Mode: RestartOnFailure, MaxRestarts: 3, Backoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	tests := []struct {
+		name    string
+		fails   int
+		action  ExhaustedAction
+		runs    int
+		wantErr string
+	}{
+		{name: "recovers", fails: 3, runs: 4},
+		{name: "gives-up", fails: 10, runs: 4, wantErr: "run flaky: gave up after 3 restarts: recovered: broker gone"},
+		{name: "keeps-going", fails: 10, action: KeepGoing, runs: 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := logtest.Install(t)
+			p := policy
+			p.OnExhausted = test.action
+			s := &flakyService{policy: p, fails: test.fails}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			err := supervise(ctx, s, env.Env{})
+			if test.wantErr == "" {
+				testutil.AssertNoErr(t, err, "supervise")
+			} else {
+				testutil.AssertEqual(t, test.wantErr, err.Error())
+			}
+			testutil.AssertEqual(t, test.runs, s.runs)
+			rec.AssertLogged(entry.LevelWarn, "flaky: failed, restart #3")
+		})
+	}
+}
+
+func TestSuperviseNever(t *testing.T) {
+	logtest.Install(t)
+	s := &flakyService{policy: RestartPolicy{}, fails: 1}
+	err := supervise(context.Background(), s, env.Env{})
+	testutil.AssertEqual(t, "run flaky: broker unavailable", err.Error())
+	testutil.AssertEqual(t, 1, s.runs)
+}
+
+func TestSuperviseLogFields(t *testing.T) {
+	rec := logtest.Install(t)
+	s := &flakyService{policy: RestartPolicy{Mode: RestartOnFailure, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}, fails: 1}
+	ctx, cancel := context.WithTimeout(log.WithContext(context.Background(), "service", "app"), 20*time.Millisecond)
+	defer cancel()
+	testutil.AssertNoErr(t, supervise(ctx, s, env.Env{}), "supervise")
+
+	testutil.AssertEqual(t, 1, len(rec.Entries()))
+	var keys []string
+	for _, f := range rec.Entries()[0].Fields {
+		keys = append(keys, f.Key)
+	}
+	testutil.AssertEqual(t, []string{"service", "service_name", "error"}, keys)
+	name, _ := rec.Entries()[0].Fields.Value("service_name")
+	testutil.AssertEqual(t, "flaky", name)
+}
+
+func TestSupervisePanicLoggedOnce(t *testing.T) {
+	rec := logtest.Install(t)
+	s := &flakyService{policy: RestartPolicy{Mode: RestartOnFailure, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}, fails: 2}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	testutil.AssertNoErr(t, supervise(ctx, s, env.Env{}), "supervise")
+
+	var panics []entry.Entry
+	for _, e := range rec.Entries() {
+		if strings.Contains(e.Message, "broker gone") {
+			panics = append(panics, e)
+		}
+	}
+	testutil.AssertEqual(t, 1, len(panics))
+	stack, _ := panics[0].Fields.Value(log.FieldStack)
+	testutil.AssertEqual(t, true, strings.Contains(stack.(string), "(*flakyService).RunCtx"))
+}